@@ -0,0 +1,207 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/portforward"
+	"github.com/mproffitt/bmx/pkg/tmux"
+	"github.com/spf13/cobra"
+)
+
+var (
+	forwardContext   string
+	forwardNamespace string
+	forwardSession   string
+)
+
+// forwardCmd is the parent for all port-forward commands
+var forwardCmd = &cobra.Command{
+	Use:   "forward",
+	Short: "manage kubernetes port-forwards for a session",
+	Long: `Forward manages kubernetes port-forwards that belong to a session.
+
+Forwards are started and supervised by bmx in the background and restarted
+if they drop. They are saved with the session and started again when the
+session is restored with 'bmx load'.
+
+Commands act on the current session unless '--session' is given.`,
+}
+
+var forwardListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the forwards for a session",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		statuses, err := portforward.Statuses(forwardTarget())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list forwards. error was %q\n", err.Error())
+			os.Exit(1)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CONTEXT\tNAMESPACE\tTARGET\tPORTS\tSTATE\tRESTARTS\tERROR")
+		for _, s := range statuses {
+			f := s.Forward
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d:%s\t%s\t%d\t%s\n", f.Context, f.Namespace,
+				f.Target, f.LocalPort, f.RemotePort, s.State, s.Restarts, s.Error)
+		}
+		_ = w.Flush()
+	},
+}
+
+var forwardAddCmd = &cobra.Command{
+	Use:   "add <target> <[local:]remote>",
+	Short: "add a forward to a session",
+	Long: `Add a port-forward to a session and start it.
+
+The target and ports are given in the same form as 'kubectl port-forward',
+for example
+
+  bmx forward add service/myservice 8443:https
+
+The current context and namespace of the session kubeconfig are used
+unless '--context' or '--namespace' are given. A forward on the same local
+port as an existing one replaces it.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		session := forwardTarget()
+		context, namespace := forwardContext, forwardNamespace
+		if context == "" {
+			status, err := kubernetes.CurrentStatus(tmux.GetTmuxEnvVar(session, "KUBECONFIG"))
+			if err != nil || status.Context == "" {
+				fmt.Fprintln(os.Stderr, "session has no current context, use '--context'")
+				os.Exit(1)
+			}
+			context = status.Context
+			if namespace == "" {
+				namespace = status.Namespace
+			}
+		}
+
+		forward, err := portforward.Parse(context, namespace, args[0], args[1])
+		if err == nil {
+			err = portforward.Add(session, forward)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to add forward. error was %q\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+var forwardRemoveCmd = &cobra.Command{
+	Use:   "rm <local port>",
+	Short: "stop and remove a forward",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		forwardPort(args[0], func(session string, port int) error {
+			return portforward.Remove(session, port)
+		})
+	},
+}
+
+var forwardStartCmd = &cobra.Command{
+	Use:   "start <local port>",
+	Short: "start a stopped forward",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		forwardPort(args[0], func(session string, port int) error {
+			return portforward.SetStopped(session, port, false)
+		})
+	},
+}
+
+var forwardStopCmd = &cobra.Command{
+	Use:   "stop <local port>",
+	Short: "stop a forward without removing it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		forwardPort(args[0], func(session string, port int) error {
+			return portforward.SetStopped(session, port, true)
+		})
+	},
+}
+
+// forwardRunCmd is started in the background by bmx and
+// is not expected to be run by hand
+var forwardRunCmd = &cobra.Command{
+	Use:    "run <session>",
+	Short:  "supervise the forwards for a session",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		err := portforward.Run(ctx, args[0], portforward.DefaultForwarder)
+		if err != nil && !errors.Is(err, portforward.ErrRunning) {
+			fmt.Fprintf(os.Stderr, "%s port-forward supervisor failed. error was %q\n",
+				time.Now().Format(time.RFC3339), err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(forwardCmd)
+	forwardCmd.AddCommand(forwardListCmd, forwardAddCmd, forwardRemoveCmd,
+		forwardStartCmd, forwardStopCmd, forwardRunCmd)
+	forwardCmd.PersistentFlags().StringVarP(&forwardSession, "session", "s", "",
+		"session the forwards belong to (defaults to the current session)")
+	forwardAddCmd.Flags().StringVarP(&forwardContext, "context", "c", "",
+		"context to forward from (defaults to the current context)")
+	forwardAddCmd.Flags().StringVar(&forwardNamespace, "namespace", "",
+		"namespace of the target (defaults to the context namespace)")
+}
+
+// Get the session the forward commands should act on
+func forwardTarget() string {
+	if forwardSession != "" {
+		return forwardSession
+	}
+	session := tmux.CurrentSession()
+	if session == "" {
+		fmt.Fprintln(os.Stderr, "not in a tmux session, use '--session'")
+		os.Exit(1)
+	}
+	return session
+}
+
+func forwardPort(arg string, action func(session string, port int) error) {
+	port, err := strconv.Atoi(arg)
+	if err == nil {
+		err = action(forwardTarget(), port)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to update forward. error was %q\n", err.Error())
+		os.Exit(1)
+	}
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/mproffitt/bmx/pkg/components/dialog"
 	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/repos"
 	"github.com/mproffitt/bmx/pkg/theme"
 	"github.com/mproffitt/bmx/pkg/tmux"
 	"github.com/spf13/cobra"
@@ -69,8 +70,17 @@ func init() {
 	killCmd.Flags().BoolVarP(&force, "force", "f", false, "force kill the current session (skips popup)")
 }
 
+// The worktree goes first as git refuses to remove one with
+// uncommitted changes, which leaves the session untouched. Killing
+// the session is last as it may also end this process
 func kill(name string) {
-	err := kubernetes.DeleteConfig(name)
+	var err error
+	if bmxConfig.RemoveWorktrees {
+		err = repos.RemoveWorktree(tmux.SessionPath(name), name)
+	}
+	if err == nil {
+		err = kubernetes.DeleteConfig(name)
+	}
 	if err == nil {
 		err = tmux.KillSession(name)
 	}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/kubernetes/ui/standalone"
+	"github.com/mproffitt/bmx/pkg/theme"
+	"github.com/mproffitt/bmx/pkg/tmux"
+	"github.com/spf13/cobra"
+)
+
+var kubeconfig string
+
+// kubeCmd is the parent for all kubeconfig commands
+//
+// Run on its own, it opens the context manager for the kubeconfig
+var kubeCmd = &cobra.Command{
+	Use:   "kube",
+	Short: "manage kubeconfig files",
+	Long: `Run without a subcommand, kube opens the kubernetes context manager for
+a kubeconfig outside of the session manager.
+
+The kubeconfig is given with '--kubeconfig' or taken from $KUBECONFIG,
+falling back to the default kube config file. This works with or without
+per-session kubeconfig files and outside of tmux. Inside tmux the manager
+opens in a popup unless '--no-popup' is given.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config := kubeconfigList()
+		if !noPopup && os.Getenv("TMUX") != "" {
+			// The popup doesn't inherit this environment so the
+			// kubeconfig is always passed through explicitly
+			err := tmux.DisplayPopup("68%", "50%", createTitle("Kubernetes Contexts"), theme.Colours.Black.Dark, []string{
+				tmuxExec, "--no-popup", "kube", "--kubeconfig", config,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "sorry, an error occurred during execution. error was %s", err.Error())
+				return err
+			}
+			return nil
+		}
+
+		run(standalone.New(bmxConfig, config))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(kubeCmd)
+	kubeCmd.PersistentFlags().StringVarP(&kubeconfig, "kubeconfig", "k", "",
+		"kubeconfig file to use (defaults to $KUBECONFIG)")
+}
+
+// Get the kubeconfig file commands should operate on
+//
+// If the `--kubeconfig` flag is not set, this is the first entry
+// in $KUBECONFIG, falling back to the default kube config file
+func kubeconfigFile() string {
+	if kubeconfig != "" {
+		return kubeconfig
+	}
+	for _, file := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+		if file != "" {
+			return file
+		}
+	}
+	return kubernetes.DefaultConfigFile()
+}
+
+// Get the full kubeconfig the context manager should show
+//
+// Unlike kubeconfigFile, this keeps every file in $KUBECONFIG
+// so contexts from shared layers are included
+func kubeconfigList() string {
+	if kubeconfig != "" {
+		return kubeconfig
+	}
+	if env := kubernetes.JoinConfigFiles(filepath.SplitList(os.Getenv("KUBECONFIG"))...); env != "" {
+		return env
+	}
+	return kubernetes.DefaultConfigFile()
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/kubernetes/ui/importer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importAll       bool
+	importContexts  []string
+	importOverwrite bool
+)
+
+var kubeImportCmd = &cobra.Command{
+	Use:   "import <file|->",
+	Short: "import contexts from a kubeconfig file",
+	Long: `Import merges contexts from another kubeconfig file into the current one.
+
+Use '-' to read the kubeconfig from stdin.
+
+Unless '--all' or '--context' are given, the contexts in the file are listed
+along with any clusters, users or contexts that clash with those already in
+the destination. Contexts can then be selected and renamed before importing.
+
+Clusters and users which already exist with identical settings are shared.
+Those which differ are imported under a new name. Contexts which already
+exist are only replaced if '--overwrite' is given.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		source, err := kubernetes.ReadImportFile(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to import kubeconfig. error was %q\n", err.Error())
+			os.Exit(1)
+		}
+
+		target := kubeconfigFile()
+		var selections []kubernetes.ImportSelection
+		switch {
+		case importAll:
+			for _, name := range kubernetes.ContextNames(source) {
+				selections = append(selections, kubernetes.ImportSelection{Name: name})
+			}
+		case len(importContexts) > 0:
+			for _, c := range importContexts {
+				name, newname, _ := strings.Cut(c, "=")
+				selections = append(selections, kubernetes.ImportSelection{
+					Name:    name,
+					NewName: newname,
+				})
+			}
+		default:
+			// stdin may already have been consumed by the kubeconfig
+			// so always read keys from the terminal
+			m := importer.NewFromConfig(source, target).Standalone()
+			run(m, tea.WithInputTTY())
+			selections = m.Selections()
+		}
+
+		if len(selections) == 0 {
+			return
+		}
+
+		results := kubernetes.ImportContexts(source, selections, importOverwrite, target)
+		fmt.Println(results.Summary("Imported"))
+		if len(results.Failed()) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	kubeCmd.AddCommand(kubeImportCmd)
+	kubeImportCmd.Flags().BoolVarP(&importAll, "all", "a", false,
+		"import all contexts without prompting")
+	kubeImportCmd.Flags().StringArrayVarP(&importContexts, "context", "c", nil,
+		"import the named context without prompting. Use name=newname to rename on import")
+	kubeImportCmd.Flags().BoolVar(&importOverwrite, "overwrite", false,
+		"replace contexts which already exist")
+}
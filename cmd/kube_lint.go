@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/tmux"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintFix      bool
+	lintSessions bool
+)
+
+var kubeLintCmd = &cobra.Command{
+	Use:   "lint [file...]",
+	Short: "check kubeconfig files for problems",
+	Long: `Lint checks kubeconfig files for problems that build up over time
+
+  unused-user              users no context refers to
+  unused-cluster           clusters no context refers to
+  duplicate-server         clusters identical to another under a different name
+  missing-file             certificate, key or token files that do not exist
+  missing-current-context  current-context naming a context that does not exist
+  missing-reference        contexts referring to a cluster or user that does not exist
+
+By default each file in $KUBECONFIG is checked. Use '--sessions' to check
+the kubeconfig of every session instead, or name the files to check.
+
+With '--fix', problems that can be fixed without losing anything in use are
+repaired. Duplicate clusters are merged, files that are also embedded as data
+are dropped and unused entries are removed. Anything else is only reported.
+
+When checking $KUBECONFIG only the first file is fixed. The files after it are
+shared layers and are only reported on unless they are named explicitly.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		remaining := 0
+		for _, target := range lintTargets(args) {
+			kubeconfig := target.kubeconfig
+			lint := kubernetes.Lint
+			if lintFix && !target.shared {
+				lint = kubernetes.Fix
+			}
+			issues, err := lint(kubeconfig)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to lint %q. error was %q\n", kubernetes.WritableConfig(kubeconfig), err.Error())
+				remaining++
+				continue
+			}
+			if len(issues) == 0 {
+				continue
+			}
+
+			if lintFix && target.shared {
+				fmt.Println(kubernetes.WritableConfig(kubeconfig), "(shared layer, not fixed)")
+			} else {
+				fmt.Println(kubernetes.WritableConfig(kubeconfig))
+			}
+			for _, issue := range issues {
+				status := ""
+				switch {
+				case issue.Fixed:
+					status = " (fixed)"
+				case issue.Fixable:
+					status = " (fixable)"
+					remaining++
+				default:
+					remaining++
+				}
+				fmt.Printf("  %s%s\n", issue, status)
+			}
+		}
+		if remaining > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	kubeCmd.AddCommand(kubeLintCmd)
+	kubeLintCmd.Flags().BoolVar(&lintFix, "fix", false,
+		"fix problems that can be fixed safely")
+	kubeLintCmd.Flags().BoolVarP(&lintSessions, "sessions", "s", false,
+		"check the kubeconfig of every session")
+}
+
+// A kubeconfig to lint
+//
+// kubeconfig is a KUBECONFIG style list with the file to check first,
+// followed by the files it is used alongside so references to shared
+// entries are resolved. Shared layers are never fixed
+type lintTarget struct {
+	kubeconfig string
+	shared     bool
+}
+
+// Work out which kubeconfigs to lint
+func lintTargets(files []string) []lintTarget {
+	targets := make([]lintTarget, 0)
+	switch {
+	case len(files) > 0:
+		for _, file := range files {
+			file = helpers.ExpandHome(file)
+			targets = append(targets, lintTarget{kubeconfig: kubernetes.JoinConfigFiles(
+				append([]string{file}, kubernetes.DefaultLayers()...)...)})
+		}
+	case lintSessions:
+		sessions, err := kubernetes.SessionConfigFiles()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list session kubeconfigs. error was %q\n", err.Error())
+			os.Exit(1)
+		}
+		names := make([]string, 0, len(sessions))
+		for name := range sessions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		running := tmux.IsRunning()
+		for _, name := range names {
+			file := sessions[name]
+			kubeconfig := kubernetes.JoinConfigFiles(append([]string{file}, kubernetes.DefaultLayers()...)...)
+			// running sessions may have changed their layers
+			if running && tmux.HasSession(name) {
+				if env := tmux.GetTmuxEnvVar(name, "KUBECONFIG"); kubernetes.WritableConfig(env) == file {
+					kubeconfig = env
+				}
+			}
+			targets = append(targets, lintTarget{kubeconfig: kubeconfig})
+		}
+	default:
+		list := kubeconfigList()
+		all := kubernetes.ConfigFiles(list)
+		for _, file := range all {
+			others := slices.DeleteFunc(slices.Clone(all), func(f string) bool { return f == file })
+			targets = append(targets, lintTarget{
+				kubeconfig: kubernetes.JoinConfigFiles(append([]string{file}, others...)...),
+				shared:     file != kubernetes.WritableConfig(list),
+			})
+		}
+	}
+	return targets
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/spf13/cobra"
+)
+
+var kubeWhereCmd = &cobra.Command{
+	Use:   "where <pattern>",
+	Short: "find which sessions hold a context",
+	Long: `Where searches the kubeconfig of every session for contexts matching the
+pattern and lists them along with the session that holds them.
+
+The pattern is matched case insensitively against the context name, its
+display name, the cluster name and the server.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := kubernetes.FindContexts(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to search session kubeconfigs. error was %q\n", err.Error())
+			os.Exit(1)
+		}
+		if len(entries) == 0 {
+			fmt.Fprintf(os.Stderr, "no contexts match %q\n", args[0])
+			os.Exit(1)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SESSION\tCONTEXT\tNAMESPACE\tSERVER")
+		for _, entry := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Session, entry.Context, entry.Namespace, entry.Server)
+		}
+		_ = w.Flush()
+	},
+}
+
+func init() {
+	kubeCmd.AddCommand(kubeWhereCmd)
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/charmbracelet/log"
 	"github.com/mproffitt/bmx/pkg/helpers"
 	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/portforward"
 	"github.com/mproffitt/bmx/pkg/tmux"
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/slices"
@@ -112,7 +113,11 @@ func createSession(session helpers.Session, wg *sync.WaitGroup) {
 	}
 
 	if bmxConfig.CreateSessionKubeConfig {
-		config, err := kubernetes.CreateConfig(session.Name)
+		layers := session.KubeLayers
+		if layers == nil {
+			layers = kubernetes.DefaultLayers()
+		}
+		config, err := kubernetes.SessionKubeconfig(session.Name, layers)
 		if err != nil {
 			log.Error("failed to create or load kubeconfig", "error", err)
 		}
@@ -122,6 +127,21 @@ func createSession(session helpers.Session, wg *sync.WaitGroup) {
 		}
 	}
 
+	// restore the contexts chosen for other providers
+	for variable, value := range session.Environment {
+		if err := tmux.SetSessionEnvironment(session.Name, variable, value); err != nil {
+			log.Error("failed to set "+variable, "session", session.Name, "error", err)
+		}
+	}
+
+	if len(session.Forwards) > 0 {
+		if err := portforward.Store(session.Name, session.Forwards); err != nil {
+			log.Error("failed to restore forwards", "session", session.Name, "error", err)
+		} else if err := portforward.Start(session.Name); err != nil {
+			log.Error("failed to start forwards", "session", session.Name, "error", err)
+		}
+	}
+
 	sort.SliceStable(session.Windows, func(i, j int) bool {
 		return session.Windows[i].Index < session.Windows[j].Index
 	})
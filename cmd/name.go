@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mproffitt/bmx/pkg/autoname"
+	"github.com/mproffitt/bmx/pkg/tmux"
+	"github.com/mproffitt/bmx/pkg/tmux/ui/manager"
+	"github.com/spf13/cobra"
+)
+
+var (
+	nameAll bool
+	nameCmd = &cobra.Command{
+		Use:   "name [session]",
+		Short: "name sessions and windows from their branch and command",
+		Long: `Name renames a session after the current branch of its repository and
+each of its windows after the command running in it, using the formats set
+under 'autoName' in the config file
+
+  autoName:
+    session: '{{.Repo}}@{{.Branch}}'
+    window: '{{.Command}}'
+
+If no session is given the current session is named. Use '--all' to name
+every session. Run it from tmux hooks to keep names in sync
+
+  set-hook -g session-created 'run-shell -b "bmx name #{hook_session_name}"'
+  set-hook -g client-session-changed 'run-shell -b "bmx name #{session_name}"'
+
+Nothing is printed unless the names cannot be set.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			namer, err := autoname.New(bmxConfig)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to read name formats. error was %q", err.Error())
+				os.Exit(1)
+			}
+
+			target := tmux.CurrentSession()
+			if len(args) > 0 {
+				target = args[0]
+			}
+			if nameAll {
+				target = ""
+			}
+
+			m, _ := manager.New()
+			_ = m.Init()
+			if err := namer.Apply(m.Items(), target); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to name sessions. error was %q", err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(nameCmd)
+	nameCmd.Flags().BoolVarP(&nameAll, "all", "a", false, "name every session")
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mproffitt/bmx/pkg/repos"
+	"github.com/spf13/cobra"
+)
+
+// reposCmd is the parent for all repository commands
+var reposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "manage the repository index",
+	Long: `The repositories offered when creating a session are kept in an index so
+they can be shown without walking every configured path.
+
+The index is revalidated in the background each time the picker opens,
+only reading repositories whose git config has changed.`,
+}
+
+var reposReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "rebuild the repository index",
+	Long: `Reindex walks every configured path and reads each repository again,
+replacing the index with what is found.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repositories, err := repos.Reindex(bmxConfig.Paths, repos.DefaultPattern, true).Wait()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write the repository index. error was %q\n", err.Error())
+			os.Exit(1)
+		}
+		fmt.Printf("indexed %d repositories\n", len(repositories))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reposCmd)
+	reposCmd.AddCommand(reposReindexCmd)
+}
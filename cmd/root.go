@@ -27,6 +27,8 @@ import (
 	"github.com/charmbracelet/log"
 	"github.com/mproffitt/bmx/pkg/config"
 	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/repos"
 	"github.com/mproffitt/bmx/pkg/theme"
 	"github.com/spf13/cobra"
 )
@@ -65,6 +67,7 @@ func Execute() {
 		log.SetLevel(log.DebugLevel)
 		log.SetOutput(f)
 	}
+	kubernetes.DiscardClientLogs()
 
 	var err error
 	bmxConfig, err = config.New()
@@ -73,6 +76,19 @@ func Execute() {
 		os.Exit(1)
 	}
 
+	kubernetes.SetDefaultLayers(bmxConfig.KubeConfigLayers)
+	naming := bmxConfig.KubeContextNaming
+	err = kubernetes.SetNaming(kubernetes.NamingStrategy(naming.Strategy), naming.Pattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid kubeContextNaming in config %q\n", err.Error())
+		os.Exit(1)
+	}
+
+	if err = repos.SetProjects(bmxConfig.Projects); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid projects in config %q\n", err.Error())
+		os.Exit(1)
+	}
+
 	err = rootCmd.Execute()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error %q", err.Error())
@@ -89,8 +105,8 @@ func init() {
 		"don't run in tmux popup")
 }
 
-func run(m tea.Model) {
-	p := tea.NewProgram(m, tea.WithAltScreen())
+func run(m tea.Model, opts ...tea.ProgramOption) {
+	p := tea.NewProgram(m, append([]tea.ProgramOption{tea.WithAltScreen()}, opts...)...)
 	if _, err := p.Run(); err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "Error running program:\n%s\n", err.Error())
 		os.Exit(1)
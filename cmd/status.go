@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/mproffitt/bmx/pkg/components/icons"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/theme"
+	"github.com/mproffitt/bmx/pkg/tmux"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status [session]",
+	Short: "print the kube context for the tmux status line",
+	Long: `Status prints the current kube context and namespace of a session as a
+tmux status line segment. Add it to your tmux.conf with
+
+  set -g status-right '#(bmx status #{session_name})'
+
+If no session is given the current session is used.
+
+The segment is coloured by environment using the 'kubeStatus' patterns in
+the config file. The result is cached until the session's kubeconfig
+changes so redrawing the status line is cheap. Nothing is printed if the
+session has no current context.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		session := tmux.CurrentSession()
+		if len(args) > 0 {
+			session = args[0]
+		}
+		kubeconfig := tmux.GetTmuxEnvVar(session, "KUBECONFIG")
+		if kubeconfig == "" {
+			return
+		}
+
+		// errors are never printed, they would end up in the status line
+		status, err := kubernetes.CurrentStatus(kubeconfig)
+		if err != nil {
+			log.Debug("failed to get kube status", "session", session, "error", err)
+		}
+		if status.Context == "" {
+			return
+		}
+		fmt.Print(statusSegment(status))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
+
+// Format the status as a tmux status line segment
+func statusSegment(status kubernetes.Status) string {
+	colour := theme.Colours.Blue.Dark
+	switch bmxConfig.KubeStatus.Environment(status.Context) {
+	case "prod":
+		colour = theme.Colours.Red.Dark
+	case "staging":
+		colour = theme.Colours.Yellow.Dark
+	case "dev":
+		colour = theme.Colours.Green.Dark
+	}
+
+	return fmt.Sprintf("#[fg=%s,bg=%s,bold] %c %s #[fg=%s,bg=%s,nobold] %s #[default]",
+		theme.Colours.Bg.Dark, colour, icons.Kubernetes, status.DisplayName,
+		colour, theme.Colours.Black.Dark, status.Namespace)
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package autoname
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/repos"
+	"github.com/mproffitt/bmx/pkg/tmux"
+	"github.com/mproffitt/bmx/pkg/tmux/ui/session"
+)
+
+// Fields are the values given to the name formats
+type Fields struct {
+	Name        string
+	Repo        string
+	Owner       string
+	Branch      string
+	Path        string
+	Command     string
+	CommandLine string
+	Index       uint64
+}
+
+// Namer keeps session and window names in sync with the
+// configured formats
+type Namer struct {
+	config  *config.Config
+	session *template.Template
+	window  *template.Template
+}
+
+// Create a namer for the formats in the config
+//
+// An error is returned if either format is not a valid template
+func New(c *config.Config) (*Namer, error) {
+	n := Namer{config: c}
+	var err error
+	if n.session, err = parse("session", c.AutoName.Session); err != nil {
+		return nil, err
+	}
+	if n.window, err = parse("window", c.AutoName.Window); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func parse(name, format string) (*template.Template, error) {
+	if format == "" {
+		return nil, nil
+	}
+	t, err := template.New(name).Option("missingkey=error").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s name format %q %w", name, format, err)
+	}
+	return t, nil
+}
+
+// Apply the formats to the target session and its windows, or to
+// every session when target is empty
+//
+// All sessions are needed to tell which names are taken. Sessions
+// are only renamed when they were started in a git
+// repository. A name already taken by another session, or still held
+// by the kubeconfig of a renamed one, is prefixed with the owner of
+// the repository, then numbered until it is free.
+// Windows with the same name in a session are numbered in order
+func (n *Namer) Apply(sessions []*session.Session, target string) error {
+	taken := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		taken = append(taken, s.Name)
+	}
+
+	for _, s := range sessions {
+		if target != "" && s.Name != target {
+			continue
+		}
+		repo := repos.ReadRepository(s.Path)
+		fields := Fields{
+			Name:  s.Name,
+			Repo:  repo.Name,
+			Owner: repo.Owner,
+			Path:  s.Path,
+		}
+		if repo.Kind == "" {
+			if status, err := repos.CachedStatus(s.Path); err == nil {
+				fields.Branch = status.Branch
+			}
+		}
+
+		// windows are targeted by the session name so go first
+		if err := n.windows(s, fields); err != nil {
+			return err
+		}
+		if n.session == nil || fields.Branch == "" {
+			continue
+		}
+
+		name, err := execute(n.session, fields)
+		if err != nil {
+			return err
+		}
+		others := slices.DeleteFunc(slices.Clone(taken), func(t string) bool {
+			return t == s.Name
+		})
+		name = unique(sessionName(name), repo.Owner, func(name string) bool {
+			return slices.Contains(others, name) || kubernetes.RenamedConfigHeld(name, s.Name)
+		})
+		if name == s.Name {
+			continue
+		}
+		if err := s.Rename(name); err != nil {
+			return err
+		}
+		taken = append(others, name)
+		if err := n.renameSaved(s.Name, name); err != nil {
+			return err
+		}
+		s.Name = name
+	}
+	return nil
+}
+
+// Name each window of the session after the command it is running
+func (n *Namer) windows(s *session.Session, fields Fields) error {
+	if n.window == nil {
+		return nil
+	}
+	used := make([]string, 0, len(s.Windows))
+	for _, w := range s.Windows {
+		fields.Index = w.Index
+		fields.CommandLine = w.Command
+		fields.Command = command(w.Command)
+		name, err := execute(n.window, fields)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = w.Name
+		}
+		name = unique(name, "", func(name string) bool {
+			return slices.Contains(used, name)
+		})
+		used = append(used, name)
+		if name != w.Name {
+			tmux.RenameWindow(fmt.Sprintf("%s:%d", s.Name, w.Index), name)
+			w.Name = name
+		}
+	}
+	return nil
+}
+
+// Keep any saved session with the old name under the new one
+func (n *Namer) renameSaved(from, to string) error {
+	sessions := slices.Clone(n.config.Sessions)
+	index := slices.IndexFunc(sessions, func(s helpers.Session) bool {
+		return s.Name == from
+	})
+	if index < 0 {
+		return nil
+	}
+	sessions[index].Name = to
+	return n.config.SetSessions(sessions)
+}
+
+func execute(t *template.Template, fields Fields) (string, error) {
+	var name strings.Builder
+	if err := t.Execute(&name, fields); err != nil {
+		return "", fmt.Errorf("failed to format name %w", err)
+	}
+	return strings.TrimSpace(name.String()), nil
+}
+
+// The name of the program from a command line
+//
+// Login shells are shown without their leading `-`
+func command(commandLine string) string {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(filepath.Base(fields[0]), "-")
+}
+
+// tmux does not allow `.` or `:` in session names
+func sessionName(name string) string {
+	return strings.NewReplacer(".", "_", ":", "_").Replace(name)
+}
+
+// Find a name that is not taken
+//
+// Candidates are always tried in the same order so names do not
+// move between runs
+func unique(name, owner string, taken func(string) bool) string {
+	candidates := []string{name}
+	if owner != "" {
+		candidates = append(candidates, owner+"-"+name)
+	}
+	for _, candidate := range candidates {
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+	base := candidates[len(candidates)-1]
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", base, i)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package aws
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Profile is a named profile from the AWS config or credentials file
+//
+// Source describes where credentials for the profile come from,
+// one of `sso`, `role`, `process` or `keys`
+type Profile struct {
+	Name   string
+	Region string
+	Source string
+}
+
+// Regions offered when choosing a region for a profile
+var Regions = []string{
+	"af-south-1", "ap-east-1", "ap-northeast-1", "ap-northeast-2",
+	"ap-northeast-3", "ap-south-1", "ap-south-2", "ap-southeast-1",
+	"ap-southeast-2", "ap-southeast-3", "ap-southeast-4", "ca-central-1",
+	"ca-west-1", "eu-central-1", "eu-central-2", "eu-north-1", "eu-south-1",
+	"eu-south-2", "eu-west-1", "eu-west-2", "eu-west-3", "il-central-1",
+	"me-central-1", "me-south-1", "sa-east-1", "us-east-1", "us-east-2",
+	"us-west-1", "us-west-2",
+}
+
+// Get the path to the AWS config file
+//
+// This honours AWS_CONFIG_FILE in the same way as the AWS cli
+func ConfigFile() string {
+	if file := os.Getenv("AWS_CONFIG_FILE"); file != "" {
+		return file
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".aws", "config")
+}
+
+// Get the path to the AWS shared credentials file
+//
+// This honours AWS_SHARED_CREDENTIALS_FILE in the same way as the AWS cli
+func CredentialsFile() string {
+	if file := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); file != "" {
+		return file
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".aws", "credentials")
+}
+
+// List the profiles found in the AWS config and credentials files
+//
+// Profiles found in both are merged, with settings from the config
+// file taking precedence. Missing files are not an error
+func Profiles() ([]Profile, error) {
+	profiles := make(map[string]*Profile)
+
+	config, err := readSections(ConfigFile())
+	if err != nil {
+		return nil, err
+	}
+	for section, values := range config {
+		// other sections such as `sso-session` and `services`
+		// are not profiles
+		name, ok := strings.CutPrefix(section, "profile ")
+		if !ok && section != "default" {
+			continue
+		}
+		profile := profile(profiles, strings.TrimSpace(name))
+		profile.Region = values["region"]
+		profile.Source = source(values)
+	}
+
+	credentials, err := readSections(CredentialsFile())
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range credentials {
+		profile := profile(profiles, name)
+		if profile.Region == "" {
+			profile.Region = values["region"]
+		}
+		if profile.Source == "" {
+			profile.Source = "keys"
+		}
+	}
+
+	list := make([]Profile, 0, len(profiles))
+	for _, p := range profiles {
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list, nil
+}
+
+func profile(profiles map[string]*Profile, name string) *Profile {
+	if _, ok := profiles[name]; !ok {
+		profiles[name] = &Profile{Name: name}
+	}
+	return profiles[name]
+}
+
+// Work out where a profile gets its credentials from
+func source(values map[string]string) string {
+	switch {
+	case values["sso_session"] != "" || values["sso_start_url"] != "":
+		return "sso"
+	case values["role_arn"] != "":
+		return "role"
+	case values["credential_process"] != "":
+		return "process"
+	case values["aws_access_key_id"] != "":
+		return "keys"
+	}
+	return ""
+}
+
+// Read the sections of an ini file into a map of key values
+//
+// Only the subset of ini used by the AWS cli is understood. Nested
+// values such as those under `s3 =` are flattened into the section
+func readSections(filename string) (map[string]map[string]string, error) {
+	sections := make(map[string]map[string]string)
+	file, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return sections, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var current map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := sections[name]; !ok {
+				sections[name] = make(map[string]string)
+			}
+			current = sections[name]
+		case current != nil:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			current[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return sections, scanner.Err()
+}
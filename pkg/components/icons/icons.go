@@ -67,6 +67,8 @@ const (
 )
 
 const (
+	Child      = '↳'
 	Ellipsis   = '…'
 	Kubernetes = '󱃾'
+	Layers     = '󰌨'
 )
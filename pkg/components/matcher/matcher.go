@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package matcher
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/sahilm/fuzzy"
+)
+
+// Separates the fields of a list item's FilterValue
+const separator = "\x1f"
+
+// Taken from the score of each field after the first so a match
+// in the name beats a similar match further along, such as in a path
+const fieldPenalty = 10
+
+// Matches are shown bold and underlined. Only those attributes are
+// switched off again afterwards so the match keeps the colours of
+// the row or cell it is drawn in
+var (
+	highlightOn  = ansi.Style{}.Bold().Underline().String()
+	highlightOff = ansi.Style{}.NormalIntensity().NoUnderline().String()
+)
+
+// Match is an item that matched the pattern
+type Match struct {
+	// The position of the item in the items searched
+	Index int
+	Score int
+
+	// The byte offsets matched in each of the item's fields
+	Matched [][]int
+}
+
+// A single field of every item, so it can be searched by fuzzy
+type column struct {
+	items [][]string
+	field int
+}
+
+func (c column) String(i int) string {
+	if c.field < len(c.items[i]) {
+		return c.items[i][c.field]
+	}
+	return ""
+}
+
+func (c column) Len() int {
+	return len(c.items)
+}
+
+// Find fuzzy matches the pattern against the fields of each item,
+// best match first
+//
+// Each word in the pattern must match one of the fields and the
+// item scores the total of its best match for each word. Fields
+// are given most important first and later fields score lower.
+// Equal scores keep the earlier field and the earlier item
+func Find(pattern string, items [][]string) []Match {
+	terms := strings.Fields(pattern)
+	if len(terms) == 0 {
+		return nil
+	}
+	fields := 0
+	for _, item := range items {
+		fields = max(fields, len(item))
+	}
+
+	matches := make([]Match, len(items))
+	found := make([]int, len(items))
+	for _, term := range terms {
+		best := make(map[int]fuzzy.Match)
+		field := make(map[int]int)
+		for f := range fields {
+			for _, match := range fuzzy.FindFromNoSort(term, column{items, f}) {
+				match.Score -= f * fieldPenalty
+				if b, ok := best[match.Index]; !ok || match.Score > b.Score {
+					best[match.Index] = match
+					field[match.Index] = f
+				}
+			}
+		}
+		for i, match := range best {
+			if matches[i].Matched == nil {
+				matches[i].Matched = make([][]int, fields)
+			}
+			matches[i].Index = i
+			matches[i].Score += match.Score
+			matches[i].Matched[field[i]] = append(matches[i].Matched[field[i]], match.MatchedIndexes...)
+			found[i]++
+		}
+	}
+
+	matches = slices.DeleteFunc(matches, func(m Match) bool {
+		return m.Matched == nil || found[m.Index] != len(terms)
+	})
+	slices.SortStableFunc(matches, func(a, b Match) int {
+		return b.Score - a.Score
+	})
+	return matches
+}
+
+// Highlight the matched bytes of s
+func Highlight(s string, matched []int) string {
+	if len(matched) == 0 {
+		return s
+	}
+	var out strings.Builder
+	on := false
+	for i, r := range s {
+		if slices.Contains(matched, i) != on {
+			on = !on
+			if on {
+				out.WriteString(highlightOn)
+			} else {
+				out.WriteString(highlightOff)
+			}
+		}
+		out.WriteRune(r)
+	}
+	if on {
+		out.WriteString(highlightOff)
+	}
+	return out.String()
+}
+
+// Join the fields of a list item to give its FilterValue
+//
+// The first field should be the item's title as that is the
+// only one the list highlights
+func Join(fields ...string) string {
+	return strings.Join(fields, separator)
+}
+
+// Filter matches list items the same way as Find
+//
+// Set it on a list with SetFilter. Items give their fields with Join
+func Filter(term string, targets []string) []list.Rank {
+	items := make([][]string, len(targets))
+	for i, target := range targets {
+		items[i] = strings.Split(target, separator)
+	}
+
+	matches := Find(term, items)
+	ranks := make([]list.Rank, len(matches))
+	for i, match := range matches {
+		ranks[i] = list.Rank{
+			Index:          match.Index,
+			MatchedIndexes: runes(items[match.Index][0], match.Matched[0]),
+		}
+	}
+	return ranks
+}
+
+// The list highlights by rune rather than by byte
+func runes(s string, matched []int) []int {
+	indexes := make([]int, 0, len(matched))
+	for _, offset := range matched {
+		indexes = append(indexes, utf8.RuneCountInString(s[:offset]))
+	}
+	return indexes
+}
@@ -20,10 +20,13 @@
 package optionlist
 
 import (
+	"slices"
+
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/evertras/bubble-table/table"
+	"github.com/mproffitt/bmx/pkg/components/matcher"
 	"github.com/mproffitt/bmx/pkg/helpers"
 	"github.com/mproffitt/bmx/pkg/theme"
 )
@@ -31,6 +34,9 @@ import (
 const (
 	columnKeyName = "name"
 	defaultWidth  = 20
+
+	// the name with any matches highlighted
+	columnKeyDisplay = "display"
 )
 
 var customBorder = table.Border{
@@ -56,12 +62,14 @@ var customBorder = table.Border{
 type OptionModel struct {
 	cols        []table.Column
 	filterInput textinput.Model
+	freeText    bool
 	height      int
 	rows        []table.Row
 	selected    string
 	styles      optionStyles
 	table       table.Model
 	title       string
+	values      []string
 	width       int
 }
 
@@ -69,6 +77,7 @@ type optionStyles struct {
 	overlay lipgloss.Style
 	filter  lipgloss.Style
 	table   lipgloss.Style
+	typed   lipgloss.Style
 }
 
 type (
@@ -80,6 +89,19 @@ type (
 	Row interface {
 		GetValue() string
 	}
+
+	// Ordered can be implemented by Options whose rows are already
+	// in the order they should be shown. Otherwise rows are sorted
+	Ordered interface {
+		Ordered() bool
+	}
+
+	// FreeText can be implemented by Options that accept values
+	// which are not in the list. The list is then shown even when
+	// it has no rows so a value can be typed in.
+	FreeText interface {
+		FreeText() bool
+	}
 )
 
 type Option struct {
@@ -108,39 +130,100 @@ func NewOptionModel[T Options](options T) *OptionModel {
 				Foreground(theme.Colours.BrightBlue).
 				Margin(1).
 				Padding(0, 2),
+			typed: lipgloss.NewStyle().
+				Foreground(theme.Colours.Green).
+				Italic(true),
 		},
 		title: options.Title(),
 	}
 
 	n.filterInput.TextStyle = n.filterInput.TextStyle.UnsetMargins()
+	n.table = table.New(nil).
+		Border(customBorder).
+		Focused(true).
+		WithBaseStyle(n.styles.table).
+		WithFooterVisibility(false).
+		WithHeaderVisibility(false).
+		WithPageSize(20)
+	n.SetOptions(options)
+	return &n
+}
 
-	maxLen := 0
+// Replace the options shown in the list
+//
+// Any filter that has been typed is kept and applied to the new
+// options, so lists can be refreshed while they are open.
+func (n *OptionModel) SetOptions(options Options) {
+	n.title = options.Title()
+	n.values = make([]string, 0)
 	for _, v := range options.Options() {
-		n.rows = append(n.rows, table.NewRow(table.RowData{columnKeyName: v.GetValue()}))
-		if len(v.GetValue()) > maxLen {
-			maxLen = len(v.GetValue())
-		}
+		n.values = append(n.values, v.GetValue())
+	}
+	if o, ok := options.(Ordered); !ok || !o.Ordered() {
+		slices.Sort(n.values)
+	}
+	if f, ok := options.(FreeText); ok {
+		n.freeText = f.FreeText()
 	}
 
-	n.cols = []table.Column{
-		table.NewColumn(columnKeyName, "", maxLen).
-			WithFiltered(true),
+	maxLen := defaultWidth
+	n.rows = make([]table.Row, 0, len(n.values))
+	for _, v := range n.values {
+		n.rows = append(n.rows, table.NewRow(table.RowData{
+			columnKeyName:    v,
+			columnKeyDisplay: v,
+		}))
+		maxLen = max(maxLen, len(v))
 	}
 
+	n.cols = []table.Column{
+		table.NewColumn(columnKeyDisplay, "", maxLen),
+	}
 	n.styles.filter = n.styles.filter.Width(maxLen)
+	n.table = n.table.WithColumns(n.cols)
+	n.filter()
+}
 
-	n.table = table.New(n.cols).
-		Border(customBorder).
-		Filtered(true).
-		Focused(true).
-		WithBaseStyle(n.styles.table).
-		WithFooterVisibility(false).
-		WithHeaderVisibility(false).
-		WithPageSize(20).
-		WithRows(n.rows).
-		SortByAsc(columnKeyName)
+// Fuzzy match the filter against the options, best match first
+//
+// An option matching the filter exactly is always put first. Lists
+// that accept free text end with a row to use the filter as typed
+// when no option matches it exactly, so a value that only partly
+// matches an option can still be entered
+func (n *OptionModel) filter() {
+	value := n.filterInput.Value()
+	if value == "" {
+		n.table = n.table.WithRows(n.rows)
+		return
+	}
 
-	return &n
+	items := make([][]string, len(n.values))
+	for i, v := range n.values {
+		items[i] = []string{v}
+	}
+	matches := matcher.Find(value, items)
+	rows := make([]table.Row, 0, len(matches)+1)
+	exact := false
+	for _, match := range matches {
+		v := n.values[match.Index]
+		row := table.NewRow(table.RowData{
+			columnKeyName:    v,
+			columnKeyDisplay: matcher.Highlight(v, match.Matched[0]),
+		})
+		if v == value {
+			exact = true
+			rows = slices.Insert(rows, 0, row)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if n.freeText && !exact {
+		rows = append(rows, table.NewRow(table.RowData{
+			columnKeyName:    value,
+			columnKeyDisplay: n.styles.typed.Render("use '" + value + "'"),
+		}))
+	}
+	n.table = n.table.WithRows(rows).WithHighlightedRow(0)
 }
 
 func (n *OptionModel) Init() tea.Cmd {
@@ -152,7 +235,7 @@ func (n *OptionModel) GetSize() (int, int) {
 }
 
 func (n *OptionModel) Overlay() helpers.UseOverlay {
-	if len(n.rows) > 0 {
+	if len(n.rows) > 0 || n.freeText {
 		return n
 	}
 	return nil
@@ -169,7 +252,8 @@ func (n *OptionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			current := n.table.HighlightedRow()
 			filter := n.filterInput.Value()
 			data := map[string]any(current.Data)
-			// default to using the table
+			// default to using the table, which offers the
+			// filter as typed on lists that accept free text
 			if name, ok := data[columnKeyName].(string); ok {
 				cmds = append(cmds, helpers.OverlayCmd(name))
 			} else if filter != "" {
@@ -180,7 +264,8 @@ func (n *OptionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			n.filterInput.Focus()
 			n.filterInput, _ = n.filterInput.Update(msg)
 			n.filterInput.Blur()
-			n.table = n.table.WithFilterInput(n.filterInput)
+			n.filter()
+			return n, nil
 		}
 	case tea.WindowSizeMsg:
 		n.width = msg.Width
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mproffitt/bmx/pkg/helpers"
@@ -37,14 +38,111 @@ const (
 
 type Config struct {
 	Paths                    []string          `yaml:"paths"`
+	AutoName                 AutoName          `yaml:"autoName,omitempty"`
+	Bookmarks                []string          `yaml:"bookmarks,omitempty"`
+	CloneLayout              string            `yaml:"cloneLayout,omitempty"`
 	CreateSessionKubeConfig  bool              `yaml:"createSessionKubeConfig"`
 	DefaultSession           string            `yaml:"defaultSession"`
+	KubeConfigLayers         []string          `yaml:"kubeConfigLayers,omitempty"`
+	KubeContextNaming        ContextNaming     `yaml:"kubeContextNaming,omitempty"`
+	KubeStatus               KubeStatus        `yaml:"kubeStatus,omitempty"`
 	ManageSessionKubeContext bool              `yaml:"manageSessionKubeContext"`
+	Projects                 Projects          `yaml:"projects,omitempty"`
+	Providers                []string          `yaml:"providers,omitempty"`
+	RemoveWorktrees          bool              `yaml:"removeWorktrees,omitempty"`
 	Theme                    string            `yaml:"theme"`
 	Sessions                 []helpers.Session `yaml:"sessions"`
 	filename                 string
 }
 
+// AutoName holds the formats sessions and windows are named by
+//
+// Each is a Go template. Sessions are given `.Repo`, `.Owner`,
+// `.Branch`, `.Path` and `.Name`, the name the session already has.
+// Windows are also given `.Command`, the program running in the
+// active pane, `.CommandLine` and `.Index`. An empty format leaves
+// the names alone
+type AutoName struct {
+	Session string `yaml:"session,omitempty"`
+	Window  string `yaml:"window,omitempty"`
+}
+
+// ContextNaming controls how kubernetes context names are displayed
+//
+// Strategy is one of `full` (the default), `regex` or `provider`.
+// Pattern is the regular expression used by the `regex` strategy.
+type ContextNaming struct {
+	Strategy string `yaml:"strategy,omitempty"`
+	Pattern  string `yaml:"pattern,omitempty"`
+}
+
+// Projects controls what is found under the configured paths
+//
+// Markers are files such as `go.mod` that mark a directory without
+// git as a project. Directories are always listed as projects whether
+// they are under a path or not. Roots holds extra options for any of
+// the configured paths
+type Projects struct {
+	Markers     []string     `yaml:"markers,omitempty"`
+	Directories []string     `yaml:"directories,omitempty"`
+	Roots       []SearchRoot `yaml:"roots,omitempty"`
+}
+
+// SearchRoot limits how one of the configured paths is searched
+//
+// Include and Exclude are globs matched against the path relative to
+// the root, where `**` matches any number of directories. A glob without
+// a `/` is matched against the directory name. MaxDepth of zero searches
+// every level
+type SearchRoot struct {
+	Path     string   `yaml:"path"`
+	MaxDepth int      `yaml:"maxDepth,omitempty"`
+	Include  []string `yaml:"include,omitempty"`
+	Exclude  []string `yaml:"exclude,omitempty"`
+}
+
+// KubeStatus holds the patterns used to colour the kube context
+// in the tmux status line by environment.
+//
+// Each is a regular expression matched against the context name.
+// Empty patterns use the defaults from DefaultKubeStatus
+type KubeStatus struct {
+	Prod    string `yaml:"prod,omitempty"`
+	Staging string `yaml:"staging,omitempty"`
+	Dev     string `yaml:"dev,omitempty"`
+}
+
+// Default patterns for each environment in the status line
+var DefaultKubeStatus = KubeStatus{
+	Prod:    `(?i)(^|[^a-z])prod`,
+	Staging: `(?i)(stag|uat|preprod)`,
+	Dev:     `(?i)(dev|test|kind|local)`,
+}
+
+// Get the environment a context belongs to
+//
+// Returns one of `prod`, `staging` or `dev`, checked in that order,
+// or an empty string if no pattern matches. Invalid patterns never match
+func (s KubeStatus) Environment(context string) string {
+	patterns := []struct {
+		name, pattern, fallback string
+	}{
+		{"prod", s.Prod, DefaultKubeStatus.Prod},
+		{"staging", s.Staging, DefaultKubeStatus.Staging},
+		{"dev", s.Dev, DefaultKubeStatus.Dev},
+	}
+	for _, p := range patterns {
+		pattern := p.pattern
+		if pattern == "" {
+			pattern = p.fallback
+		}
+		if re, err := regexp.Compile(pattern); err == nil && re.MatchString(context) {
+			return p.name
+		}
+	}
+	return ""
+}
+
 const (
 	DefaultDarkTheme  = "tokyo_night"
 	DefaultLightTheme = "tokyo_night_day"
@@ -21,6 +21,7 @@ package exec
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 
@@ -136,8 +137,19 @@ func (t *BmxExecError) SetError(err error) {
 }
 
 func execCmd(command string, args []string) (string, string, error) {
+	return execEnvCmd(nil, command, args)
+}
+
+// Run the command with additional environment variables
+//
+// `env` is in the form `KEY=value` and is added to the
+// environment of the current process
+func execEnvCmd(env []string, command string, args []string) (string, string, error) {
 	log.Debug(command + " " + strings.Join(args, " "))
 	cmd := exec.Command(command, args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	var stdout strings.Builder
 	var stderr strings.Builder
 	cmd.Stdout = &stdout
@@ -172,5 +184,6 @@ func execSilentCmd(command string, args []string) error {
 
 var (
 	Exec       = execCmd
+	ExecEnv    = execEnvCmd
 	ExecSilent = execSilentCmd
 )
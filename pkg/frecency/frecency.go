@@ -0,0 +1,275 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package frecency
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"gopkg.in/yaml.v3"
+)
+
+type Kind string
+
+const (
+	Repositories Kind = "repository"
+	Sessions     Kind = "session"
+)
+
+const (
+	storeFile  = "frecency.yaml"
+	lockSuffix = ".lock"
+)
+
+// When the total rank goes over maxRank every entry is aged so
+// older entries fall away and the store stays small
+const maxRank = 1000
+
+// Entries not visited for this long are dropped
+const maxAge = 90 * 24 * time.Hour
+
+// Store is the on-disk record of when sessions and repositories
+// were opened, used to rank them by how often and how recently
+// they were used
+type Store struct {
+	Entries []Entry `yaml:"entries"`
+}
+
+// Entry records the visits to a single session or repository
+type Entry struct {
+	Kind    Kind      `yaml:"kind"`
+	Key     string    `yaml:"key"`
+	Rank    float64   `yaml:"rank"`
+	Visited time.Time `yaml:"visited"`
+}
+
+// guards the read-modify-write of the store within this process.
+// lockStore guards it against other bmx processes
+var mu sync.Mutex
+
+// Visit records that the session or repository was opened
+func Visit(kind Kind, key string) error {
+	if key == "" {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	unlock, err := lockStore()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	store := load()
+	now := time.Now()
+	// prune first so the entry being visited is never aged out
+	store.prune(now)
+	i := store.find(kind, key)
+	if i < 0 {
+		store.Entries = append(store.Entries, Entry{Kind: kind, Key: key})
+		i = len(store.Entries) - 1
+	}
+	store.Entries[i].Rank++
+	store.Entries[i].Visited = now
+	return store.save()
+}
+
+// Rename carries the visits of an entry over to its new key
+func Rename(kind Kind, from, to string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	unlock, err := lockStore()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	store := load()
+	i := store.find(kind, from)
+	if i < 0 {
+		return nil
+	}
+	if j := store.find(kind, to); j >= 0 {
+		store.Entries[i].Rank += store.Entries[j].Rank
+		if store.Entries[j].Visited.After(store.Entries[i].Visited) {
+			store.Entries[i].Visited = store.Entries[j].Visited
+		}
+		store.Entries = slices.Delete(store.Entries, j, j+1)
+		i = store.find(kind, from)
+	}
+	store.Entries[i].Key = to
+	return store.save()
+}
+
+// Scores gives the frecency of every entry of the given kind
+//
+// Anything never visited is missing from the map and so scores zero
+func Scores(kind Kind) map[string]float64 {
+	mu.Lock()
+	defer mu.Unlock()
+	now := time.Now()
+	scores := make(map[string]float64)
+	for _, e := range load().Entries {
+		if e.Kind == kind {
+			scores[e.Key] = e.score(now)
+		}
+	}
+	return scores
+}
+
+// Normalise scales scores to between 0 and 1 relative to the highest
+// so scores from different sources can be compared
+func Normalise(scores map[string]float64) map[string]float64 {
+	highest := 0.0
+	for _, score := range scores {
+		highest = max(highest, score)
+	}
+	normalised := make(map[string]float64, len(scores))
+	for key, score := range scores {
+		if highest > 0 {
+			normalised[key] = score / highest
+		}
+	}
+	return normalised
+}
+
+// The rank weighted by how long ago the entry was last visited
+func (e Entry) score(now time.Time) float64 {
+	switch age := now.Sub(e.Visited); {
+	case age < time.Hour:
+		return e.Rank * 4
+	case age < 24*time.Hour:
+		return e.Rank * 2
+	case age < 7*24*time.Hour:
+		return e.Rank / 2
+	}
+	return e.Rank / 4
+}
+
+func (s *Store) find(kind Kind, key string) int {
+	return slices.IndexFunc(s.Entries, func(e Entry) bool {
+		return e.Kind == kind && e.Key == key
+	})
+}
+
+// Drop stale entries and age the rest once the store grows too large
+//
+// Repositories which no longer exist on disk are dropped as well
+func (s *Store) prune(now time.Time) {
+	var total float64
+	for _, e := range s.Entries {
+		total += e.Rank
+	}
+	factor := 1.0
+	if total > maxRank {
+		factor = 0.9 * maxRank / total
+	}
+	s.Entries = slices.DeleteFunc(s.Entries, func(e Entry) bool {
+		if now.Sub(e.Visited) > maxAge {
+			return true
+		}
+		if e.Kind == Repositories {
+			if _, err := os.Stat(e.Key); os.IsNotExist(err) {
+				return true
+			}
+		}
+		return false
+	})
+	for i := range s.Entries {
+		s.Entries[i].Rank *= factor
+	}
+	s.Entries = slices.DeleteFunc(s.Entries, func(e Entry) bool {
+		return e.Rank < 1
+	})
+}
+
+func storePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, helpers.ExecutableName(), storeFile)
+}
+
+// Lock the store for writing
+//
+// Popups and the status line each run their own bmx so visits are
+// recorded by several processes at once. This uses the same
+// `<filename>.lock` convention as kubeconfig files but as an flock,
+// which the kernel releases if bmx dies, so a crash never leaves
+// the store locked.
+//
+// The returned function releases the lock
+func lockStore() (func(), error) {
+	filename := storePath() + lockSuffix
+	if err := os.MkdirAll(filepath.Dir(filename), 0750); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock frecency store %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to lock frecency store %w", err)
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		_ = f.Close()
+	}, nil
+}
+
+// A missing or unreadable store is treated as empty
+func load() Store {
+	var store Store
+	if content, err := os.ReadFile(storePath()); err == nil {
+		_ = yaml.Unmarshal(content, &store)
+	}
+	return store
+}
+
+func (s *Store) save() error {
+	filename := storePath()
+	content, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filename), storeFile)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package frecency
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScore(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		age  time.Duration
+		want float64
+	}{
+		{name: "just now", age: 0, want: 8},
+		{name: "within the hour", age: 59 * time.Minute, want: 8},
+		{name: "an hour ago", age: time.Hour, want: 4},
+		{name: "within the day", age: 23 * time.Hour, want: 4},
+		{name: "a day ago", age: 24 * time.Hour, want: 1},
+		{name: "within the week", age: 6 * 24 * time.Hour, want: 1},
+		{name: "a week ago", age: 7 * 24 * time.Hour, want: 0.5},
+		{name: "months ago", age: 80 * 24 * time.Hour, want: 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Entry{Kind: Sessions, Key: "api", Rank: 2, Visited: now.Add(-tt.age)}
+			if got := e.score(now); got != tt.want {
+				t.Errorf("score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Now()
+	repository := t.TempDir()
+	missing := filepath.Join(repository, "missing")
+
+	tests := []struct {
+		name    string
+		entries []Entry
+		// ranks keyed by kind and key
+		want map[string]float64
+	}{
+		{
+			name: "keeps entries up to the maximum age",
+			entries: []Entry{
+				{Kind: Sessions, Key: "old", Rank: 5, Visited: now.Add(-maxAge)},
+				{Kind: Sessions, Key: "stale", Rank: 5, Visited: now.Add(-maxAge - time.Minute)},
+			},
+			want: map[string]float64{"session old": 5},
+		},
+		{
+			// sessions are named, not paths, so are never checked on disk
+			name: "drops repositories that no longer exist",
+			entries: []Entry{
+				{Kind: Repositories, Key: repository, Rank: 2, Visited: now},
+				{Kind: Repositories, Key: missing, Rank: 2, Visited: now},
+				{Kind: Sessions, Key: missing, Rank: 2, Visited: now},
+			},
+			want: map[string]float64{"repository " + repository: 2, "session " + missing: 2},
+		},
+		{
+			name: "leaves ranks alone up to the maximum rank",
+			entries: []Entry{
+				{Kind: Sessions, Key: "api", Rank: 600, Visited: now},
+				{Kind: Sessions, Key: "web", Rank: 400, Visited: now},
+			},
+			want: map[string]float64{"session api": 600, "session web": 400},
+		},
+		{
+			name: "ages every entry over the maximum rank",
+			entries: []Entry{
+				{Kind: Sessions, Key: "api", Rank: 1500, Visited: now},
+				{Kind: Sessions, Key: "web", Rank: 500, Visited: now},
+			},
+			want: map[string]float64{"session api": 675, "session web": 225},
+		},
+		{
+			name: "drops entries aged below a single visit",
+			entries: []Entry{
+				{Kind: Sessions, Key: "api", Rank: 1100, Visited: now},
+				{Kind: Sessions, Key: "web", Rank: 1, Visited: now},
+			},
+			want: map[string]float64{"session api": 1100 * 0.9 * maxRank / 1101},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := Store{Entries: tt.entries}
+			store.prune(now)
+			if len(store.Entries) != len(tt.want) {
+				t.Fatalf("entries = %+v, want %v", store.Entries, tt.want)
+			}
+			for _, e := range store.Entries {
+				key := string(e.Kind) + " " + e.Key
+				if want, ok := tt.want[key]; !ok || math.Abs(e.Rank-want) > 1e-9 {
+					t.Errorf("%s rank = %v, want %v", key, e.Rank, want)
+				}
+			}
+		})
+	}
+}
@@ -90,11 +90,35 @@ type UseOverlay interface {
 }
 
 // Session is a light wrapper for a tmux session
+//
+// KubeLayers holds the shared kubeconfig files used by the session.
+// If it is missing, the default layers are used when it is restored
+//
+// Forwards holds the kubernetes port-forwards run for the session and
+// Environment the variables set by context providers other than
+// kubernetes, such as the selected cloud profile
 type Session struct {
-	Command string   `yaml:"command"`
-	Name    string   `yaml:"name"`
-	Path    string   `yaml:"path"`
-	Windows []Window `yaml:"windows"`
+	Command     string            `yaml:"command"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+	Forwards    []Forward         `yaml:"forwards,omitempty"`
+	KubeLayers  []string          `yaml:"kubeLayers"`
+	Name        string            `yaml:"name"`
+	Path        string            `yaml:"path"`
+	Windows     []Window          `yaml:"windows"`
+}
+
+// Forward is a kubernetes port-forward declared for a session
+//
+// Target is in the same form as `kubectl port-forward`, for example
+// `service/myservice` or `pod/mypod`. RemotePort may be a port
+// number or a named port on the target
+type Forward struct {
+	Context    string `json:"context" yaml:"context"`
+	Namespace  string `json:"namespace" yaml:"namespace"`
+	Target     string `json:"target" yaml:"target"`
+	LocalPort  int    `json:"localPort" yaml:"localPort"`
+	RemotePort string `json:"remotePort" yaml:"remotePort"`
+	Stopped    bool   `json:"stopped,omitempty" yaml:"stopped,omitempty"`
 }
 
 // Window is a light wrapper for a tmux window
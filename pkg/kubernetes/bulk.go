@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Results holds the outcome of a bulk operation keyed by context name
+//
+// A nil error indicates the operation succeeded for that context
+type Results map[string]error
+
+// Get the names of all contexts the operation succeeded for
+func (r Results) Succeeded() []string {
+	names := make([]string, 0)
+	for name, err := range r {
+		if err == nil {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Get the names of all contexts the operation failed for
+func (r Results) Failed() []string {
+	names := make([]string, 0)
+	for name, err := range r {
+		if err != nil {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Summarise the results of the operation
+//
+// `action` is the past tense of the operation carried out, for example
+// "Moved" or "Deleted"
+func (r Results) Summary(action string) string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("%s %d of %d contexts", action, len(r.Succeeded()), len(r)))
+	for _, name := range r.Failed() {
+		builder.WriteString(fmt.Sprintf("\n\n%s: %s", name, r[name].Error()))
+	}
+	return builder.String()
+}
+
+func failAll(names []string, err error) Results {
+	results := make(Results)
+	for _, name := range names {
+		results[name] = err
+	}
+	return results
+}
+
+// Delete multiple contexts from the config in a single operation
+func DeleteContexts(names []string, filename string) Results {
+	results := make(Results)
+	err := modifyLayered(filename, func(writable, merged *api.Config) error {
+		for _, name := range names {
+			if results[name] = checkWritable(name, filename, writable, merged); results[name] != nil {
+				continue
+			}
+			deleteContext(writable, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return failAll(names, err)
+	}
+	return results
+}
+
+// Set the namespace on multiple contexts in a single operation
+func SetNamespaces(names []string, namespace, filename string) Results {
+	results := make(Results)
+	err := modifyLayered(filename, func(writable, merged *api.Config) error {
+		for _, name := range names {
+			results[name] = setNamespace(name, namespace, filename, writable, merged)
+		}
+		return nil
+	})
+	if err != nil {
+		return failAll(names, err)
+	}
+	return results
+}
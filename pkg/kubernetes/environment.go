@@ -45,18 +45,32 @@ users: []
 // If the ~/.kube directory does not exist, this will first be created
 // with permissions of 0700 and the file created under that with the name
 // `config-<sessionName>` and permissions of 0600
+//
+// An error is returned if the name is still held by a session that
+// was renamed away from it
 func CreateConfig(sessionName string) (string, error) {
 	if err := createKubeDirIfNotExist(); err != nil {
 		return "", err
 	}
-	home, _ := os.UserHomeDir()
-	sessionFile := strings.Join([]string{defaultConfigFile, sessionName}, "-")
-	configFile := filepath.Join(home, defaultConfigDir, sessionFile)
-	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		err := os.WriteFile(configFile, []byte(contents), 0600)
+	configFile := SessionConfigFile(sessionName)
+	if _, err := os.Stat(configFile); os.IsNotExist(err) || isRenamedLink(configFile) {
+		unlock, err := lockConfig(configFile)
 		if err != nil {
 			return "", err
 		}
+		defer unlock()
+
+		if RenamedConfigHeld(sessionName, "") {
+			return "", fmt.Errorf("kubeconfig %q is still used by a renamed session", configFile)
+		}
+		// another process may have created it whilst we waited for the lock
+		if _, err := os.Stat(configFile); os.IsNotExist(err) {
+			// a link left by a renamed session that has since gone
+			_ = os.Remove(configFile)
+			if err := writeFileAtomic(configFile, []byte(contents), 0600); err != nil {
+				return "", err
+			}
+		}
 	}
 
 	return configFile, nil
@@ -70,18 +84,97 @@ func DefaultConfigFile() string {
 	return filepath.Join(home, defaultConfigDir, defaultConfigFile)
 }
 
-// Delete the config file with the current session name as suffix
-func DeleteConfig(sessionName string) error {
+// Gets the path of the kubeconfig file owned by the named session
+//
+// This function does not test if the file exists
+func SessionConfigFile(sessionName string) string {
 	home, _ := os.UserHomeDir()
 	sessionFile := strings.Join([]string{defaultConfigFile, sessionName}, "-")
-	configFile := filepath.Join(home, defaultConfigDir, sessionFile)
+	return filepath.Join(home, defaultConfigDir, sessionFile)
+}
 
+// Delete the config file with the current session name as suffix
+func DeleteConfig(sessionName string) error {
+	configFile := SessionConfigFile(sessionName)
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
 		return nil
 	}
+
+	unlock, err := lockConfig(configFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	return os.Remove(configFile)
 }
 
+// Move the config file owned by a session to its new name
+//
+// A link is left at the old name so shells already running in the
+// session keep using the same file. The old name cannot be given to
+// another session until the renamed session is gone, but the session
+// may be renamed back to it. Returns the path of the file under its
+// new name
+func RenameConfig(from, to string) (string, error) {
+	oldFile, newFile := SessionConfigFile(from), SessionConfigFile(to)
+	unlock, err := lockConfigs(oldFile, newFile)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	if _, err := os.Lstat(newFile); err == nil {
+		if !isRenamedLink(newFile) {
+			return "", fmt.Errorf("kubeconfig %q already exists", newFile)
+		}
+		if RenamedConfigHeld(to, from) {
+			return "", fmt.Errorf("kubeconfig %q is still used by a renamed session", newFile)
+		}
+		_ = os.Remove(newFile)
+	}
+	if err := os.Rename(oldFile, newFile); err != nil {
+		return "", fmt.Errorf("failed to rename kubeconfig %q %w", oldFile, err)
+	}
+	if err := os.Symlink(newFile, oldFile); err != nil {
+		return newFile, fmt.Errorf("failed to link kubeconfig %q %w", oldFile, err)
+	}
+	return newFile, nil
+}
+
+// Is the file a link left behind when a session was renamed
+//
+// These always point at another session config file
+func isRenamedLink(configFile string) bool {
+	target, err := os.Readlink(configFile)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(target, SessionConfigFile(""))
+}
+
+// Is the session name held by the link left when another
+// session was renamed away from it
+//
+// Shells started before the rename still read the config through
+// the link until the renamed session is gone. The name is not held
+// against `owner`, the session the link points at, so it can be
+// renamed back
+func RenamedConfigHeld(sessionName, owner string) bool {
+	configFile := SessionConfigFile(sessionName)
+	if !isRenamedLink(configFile) {
+		return false
+	}
+	target, err := filepath.EvalSymlinks(configFile)
+	if err != nil {
+		return false
+	}
+	if owner == "" {
+		return true
+	}
+	file, err := filepath.EvalSymlinks(SessionConfigFile(owner))
+	return err != nil || target != file
+}
+
 func createKubeDirIfNotExist() error {
 	home, _ := os.UserHomeDir()
 	kubeDir := filepath.Join(home, defaultConfigDir)
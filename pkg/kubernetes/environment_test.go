@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRenamedConfigKeepsItsName(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range []string{"a", "c"} {
+		if _, err := CreateConfig(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := RenameConfig("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !RenamedConfigHeld("a", "") {
+		t.Error("the old name is not held by the renamed session")
+	}
+	if _, err := CreateConfig("a"); err == nil {
+		t.Error("a new session took the config of the renamed session")
+	}
+	if _, err := RenameConfig("c", "a"); err == nil {
+		t.Error("another session was renamed onto the config of the renamed session")
+	}
+
+	// the renamed session can go back to its old name
+	if _, err := RenameConfig("b", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(SessionConfigFile("a")); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("config for a is not a file after renaming back, err %v", err)
+	}
+
+	// once the renamed session is gone its old name is free
+	if err := DeleteConfig("a"); err != nil {
+		t.Fatal(err)
+	}
+	if RenamedConfigHeld("b", "") {
+		t.Error("the name is held after the renamed session was deleted")
+	}
+	if _, err := CreateConfig("b"); err != nil {
+		t.Errorf("failed to reuse the name of a deleted session %v", err)
+	}
+	if info, err := os.Lstat(SessionConfigFile("b")); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("config for b is not a file, err %v", err)
+	}
+}
@@ -0,0 +1,351 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ImportConflict describes which parts of a context being
+// imported clash with entries already in the destination config
+type ImportConflict int
+
+const (
+	// The context name is already in use in the destination
+	ContextConflict ImportConflict = 1 << iota
+
+	// A different cluster with the same name exists in the destination
+	ClusterConflict
+
+	// A different user with the same name exists in the destination
+	UserConflict
+)
+
+// ImportSelection identifies a context to import and the name it
+// should be given in the destination config
+//
+// If NewName is empty, the original name is kept
+type ImportSelection struct {
+	Name    string
+	NewName string
+}
+
+// ImportCandidate describes a context in a file being imported and
+// how it would be merged into the destination config
+type ImportCandidate struct {
+	// Name of the context in the file being imported
+	Name string
+
+	// Name the context will have once imported
+	NewName string
+
+	// Names the cluster and user will have once imported.
+	//
+	// These only differ from the original names when an entry with the
+	// same name but different content already exists in the destination
+	Cluster string
+	User    string
+
+	Server    string
+	Namespace string
+	Conflicts ImportConflict
+
+	sourceCluster string
+	sourceUser    string
+}
+
+// Does the candidate clash with anything in the destination
+func (c ImportCandidate) HasConflict(conflict ImportConflict) bool {
+	return c.Conflicts&conflict != 0
+}
+
+// Describe each conflict and how it will be resolved
+func (c ImportCandidate) Describe() []string {
+	descriptions := make([]string, 0)
+	if c.HasConflict(ContextConflict) {
+		descriptions = append(descriptions,
+			fmt.Sprintf("context %q already exists", c.NewName))
+	}
+	if c.HasConflict(ClusterConflict) {
+		descriptions = append(descriptions,
+			fmt.Sprintf("cluster %q differs, importing as %q", c.sourceCluster, c.Cluster))
+	}
+	if c.HasConflict(UserConflict) {
+		descriptions = append(descriptions,
+			fmt.Sprintf("user %q differs, importing as %q", c.sourceUser, c.User))
+	}
+	return descriptions
+}
+
+// Read a kubeconfig to be imported
+//
+// If `filename` is `-` the config is read from stdin
+func ReadImportFile(filename string) (*api.Config, error) {
+	var (
+		content []byte
+		err     error
+	)
+	if filename == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig %q %w", filename, err)
+	}
+	return ParseImport(content)
+}
+
+// Parse the content of a kubeconfig to be imported
+func ParseImport(content []byte) (*api.Config, error) {
+	if len(strings.TrimSpace(string(content))) == 0 {
+		return nil, errors.New("kubeconfig is empty")
+	}
+	config, err := clientcmd.Load(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig %w", err)
+	}
+	if len(config.Contexts) == 0 {
+		return nil, errors.New("kubeconfig does not contain any contexts")
+	}
+	return config, nil
+}
+
+// Get the names of all contexts in a config in sorted order
+func ContextNames(config *api.Config) []string {
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Preview how the selected contexts would be imported into `filename`
+//
+// Every context in the source is previewed if `selections` is empty
+func PreviewImport(source *api.Config, selections []ImportSelection, filename string) ([]ImportCandidate, error) {
+	if len(selections) == 0 {
+		for _, name := range ContextNames(source) {
+			selections = append(selections, ImportSelection{Name: name})
+		}
+	}
+
+	target, err := loadMerged(filename)
+	if err != nil {
+		return nil, err
+	}
+	return planImport(source, target, selections)
+}
+
+// Import the selected contexts from `source` into `filename`
+//
+// Clusters and users that already exist with identical content are
+// shared, those which differ are renamed. Contexts whose new name
+// already exists are only replaced when `overwrite` is true,
+// otherwise they are reported as failed.
+//
+// Names are checked against every layer in `filename` so an import
+// can never shadow an entry in a shared file, but only the session
+// file is written to.
+func ImportContexts(source *api.Config, selections []ImportSelection, overwrite bool, filename string) Results {
+	names := make([]string, 0, len(selections))
+	for _, s := range selections {
+		names = append(names, s.Name)
+	}
+
+	results := make(Results)
+	err := modifyLayered(filename, func(config, merged *api.Config) error {
+		// drop any context being replaced first so its cluster and
+		// user don't get treated as conflicts
+		if overwrite {
+			for _, s := range selections {
+				if _, ok := config.Contexts[importName(s)]; ok {
+					deleteContext(config, importName(s))
+					deleteContext(merged, importName(s))
+				}
+			}
+		}
+
+		candidates, err := planImport(source, merged, selections)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range candidates {
+			if c.HasConflict(ContextConflict) {
+				results[c.Name] = fmt.Errorf("context %q already exists in %q", c.NewName, filename)
+				continue
+			}
+
+			context := source.Contexts[c.Name].DeepCopy()
+			context.Cluster = c.Cluster
+			context.AuthInfo = c.User
+			context.LocationOfOrigin = ""
+
+			config.Contexts[c.NewName] = context
+
+			// identical entries already available from any layer are reused
+			if _, ok := merged.Clusters[c.Cluster]; !ok {
+				cluster := source.Clusters[c.sourceCluster].DeepCopy()
+				cluster.LocationOfOrigin = ""
+				config.Clusters[c.Cluster] = cluster
+				merged.Clusters[c.Cluster] = cluster
+			}
+			if _, ok := merged.AuthInfos[c.User]; !ok {
+				user := source.AuthInfos[c.sourceUser].DeepCopy()
+				user.LocationOfOrigin = ""
+				config.AuthInfos[c.User] = user
+				merged.AuthInfos[c.User] = user
+			}
+			results[c.Name] = nil
+		}
+		return nil
+	})
+	if err != nil {
+		return failAll(names, err)
+	}
+	return results
+}
+
+func importName(s ImportSelection) string {
+	if s.NewName != "" {
+		return s.NewName
+	}
+	return s.Name
+}
+
+// Work out the names each context, cluster and user will be given
+// when merged into the target config
+func planImport(source, target *api.Config, selections []ImportSelection) ([]ImportCandidate, error) {
+	clusters := make(map[string]string)
+	users := make(map[string]string)
+	seen := make(map[string]bool)
+
+	candidates := make([]ImportCandidate, 0, len(selections))
+	for _, s := range selections {
+		context, ok := source.Contexts[s.Name]
+		if !ok {
+			return nil, fmt.Errorf("context %q not found in kubeconfig being imported", s.Name)
+		}
+		cluster, ok := source.Clusters[context.Cluster]
+		if !ok {
+			return nil, fmt.Errorf("cluster %q for context %q not found", context.Cluster, s.Name)
+		}
+		user, ok := source.AuthInfos[context.AuthInfo]
+		if !ok {
+			return nil, fmt.Errorf("user %q for context %q not found", context.AuthInfo, s.Name)
+		}
+
+		c := ImportCandidate{
+			Name:          s.Name,
+			NewName:       importName(s),
+			Server:        cluster.Server,
+			Namespace:     context.Namespace,
+			sourceCluster: context.Cluster,
+			sourceUser:    context.AuthInfo,
+		}
+		if c.Namespace == "" {
+			c.Namespace = "default"
+		}
+
+		if _, ok := target.Contexts[c.NewName]; ok || seen[c.NewName] {
+			c.Conflicts |= ContextConflict
+		}
+		seen[c.NewName] = true
+
+		var renamed bool
+		c.Cluster, renamed = resolveImportName(context.Cluster, cluster, target.Clusters, clusters, clustersEqual)
+		if renamed {
+			c.Conflicts |= ClusterConflict
+		}
+		c.User, renamed = resolveImportName(context.AuthInfo, user, target.AuthInfos, users, usersEqual)
+		if renamed {
+			c.Conflicts |= UserConflict
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// Find the name an imported cluster or user should be stored under
+//
+// Entries are reused if the destination already holds an identical
+// one, otherwise a free name is generated by adding a numeric suffix.
+// `mapped` records names already chosen during this import so entries
+// shared by several imported contexts are only added once, and so a
+// name claimed by one entry is never given to another. An entry named
+// `a-1` is renamed if `a-1` was already given to a conflicting `a`.
+func resolveImportName[T any](name string, item T, existing map[string]T, mapped map[string]string, equal func(a, b T) bool) (string, bool) {
+	if resolved, ok := mapped[name]; ok {
+		return resolved, resolved != name
+	}
+
+	used := func(candidate string) bool {
+		for _, v := range mapped {
+			if v == candidate {
+				return true
+			}
+		}
+		return false
+	}
+
+	current, ok := existing[name]
+	if (ok && equal(current, item)) || (!ok && !used(name)) {
+		mapped[name] = name
+		return name, false
+	}
+
+	// an earlier import may already have stored this entry under a
+	// suffixed name in which case it is reused rather than duplicated
+	for i := 1; ; i++ {
+		resolved := fmt.Sprintf("%s-%d", name, i)
+		if current, ok := existing[resolved]; ok {
+			if !equal(current, item) {
+				continue
+			}
+		} else if used(resolved) {
+			continue
+		}
+		mapped[name] = resolved
+		return resolved, true
+	}
+}
+
+func clustersEqual(a, b *api.Cluster) bool {
+	a, b = a.DeepCopy(), b.DeepCopy()
+	a.LocationOfOrigin, b.LocationOfOrigin = "", ""
+	return reflect.DeepEqual(a, b)
+}
+
+func usersEqual(a, b *api.AuthInfo) bool {
+	a, b = a.DeepCopy(), b.DeepCopy()
+	a.LocationOfOrigin, b.LocationOfOrigin = "", ""
+	return reflect.DeepEqual(a, b)
+}
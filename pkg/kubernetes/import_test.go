@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestImportKeepsClaimedNamesApart(t *testing.T) {
+	source := api.NewConfig()
+	source.Clusters["a"] = &api.Cluster{Server: "https://a.example.com"}
+	source.Clusters["a-1"] = &api.Cluster{Server: "https://a-1.example.com"}
+	source.AuthInfos["admin"] = &api.AuthInfo{Token: "secret"}
+	source.Contexts["one"] = &api.Context{Cluster: "a", AuthInfo: "admin"}
+	source.Contexts["two"] = &api.Context{Cluster: "a-1", AuthInfo: "admin"}
+
+	// the conflicting `a` must not be given the name of `a-1`
+	// whichever context is imported first
+	for _, order := range [][]string{{"one", "two"}, {"two", "one"}} {
+		to := writeKubeconfig(t,
+			map[string][2]string{"existing": {"a", "admin"}},
+			map[string]string{"a": "https://existing.example.com"},
+			map[string]string{"admin": "secret"})
+
+		selections := make([]ImportSelection, 0, len(order))
+		for _, name := range order {
+			selections = append(selections, ImportSelection{Name: name})
+		}
+		results := ImportContexts(source, selections, false, to)
+		if failed := results.Failed(); len(failed) != 0 {
+			t.Fatalf("order %v failed to import %v: %v", order, failed, results)
+		}
+
+		config := readKubeconfig(t, to)
+		if server := config.Clusters["a"].Server; server != "https://existing.example.com" {
+			t.Errorf("order %v existing cluster server = %q, want it unchanged", order, server)
+		}
+		for _, name := range order {
+			context := config.Contexts[name]
+			if context == nil {
+				t.Fatalf("order %v context %q was not imported", order, name)
+			}
+			want := source.Clusters[source.Contexts[name].Cluster].Server
+			if server := config.Clusters[context.Cluster].Server; server != want {
+				t.Errorf("order %v context %q uses cluster %q with server %q, want %q",
+					order, name, context.Cluster, server, want)
+			}
+		}
+		if len(config.Clusters) != 3 {
+			t.Errorf("order %v got %d clusters, want 3", order, len(config.Clusters))
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IndexEntry describes a context held in a session's kubeconfig
+type IndexEntry struct {
+	Session     string
+	Context     string
+	DisplayName string
+	Cluster     string
+	Server      string
+	Namespace   string
+	File        string
+}
+
+// Does the entry match the pattern
+//
+// Patterns are matched case insensitively against the context name,
+// its display name, the cluster name and the server. An empty
+// pattern matches everything
+func (e IndexEntry) Matches(pattern string) bool {
+	pattern = strings.ToLower(pattern)
+	for _, value := range []string{e.Context, e.DisplayName, e.Cluster, e.Server} {
+		if strings.Contains(strings.ToLower(value), pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Build an index of the contexts held by every session
+//
+// The index is read from the `config-<session>` files in the kube
+// directory. Only the session's own file is read so contexts from
+// shared layers, which are visible to many sessions, are not
+// included. Files which cannot be read are skipped.
+//
+// Entries are sorted by session then by context name
+func ContextIndex() ([]IndexEntry, error) {
+	files, err := SessionConfigFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	index := make([]IndexEntry, 0)
+	for session, file := range files {
+		config, err := loadConfig(file)
+		if err != nil {
+			continue
+		}
+		names := displayNames(config)
+		for name, ctx := range config.Contexts {
+			entry := IndexEntry{
+				Session:     session,
+				Context:     name,
+				DisplayName: names[name],
+				Cluster:     ctx.Cluster,
+				Namespace:   ctx.Namespace,
+				File:        file,
+			}
+			if cluster, ok := config.Clusters[ctx.Cluster]; ok {
+				entry.Server = cluster.Server
+			}
+			if entry.Namespace == "" {
+				entry.Namespace = "default"
+			}
+			index = append(index, entry)
+		}
+	}
+
+	sort.SliceStable(index, func(i, j int) bool {
+		if index[i].Session == index[j].Session {
+			return index[i].Context < index[j].Context
+		}
+		return index[i].Session < index[j].Session
+	})
+	return index, nil
+}
+
+// List the kubeconfig files owned by sessions, keyed by session name
+//
+// These are the `config-<session>` files in the kube directory. The
+// sessions they belong to may no longer be running. Links left behind
+// by renamed sessions are skipped as the file is listed under its
+// new name
+func SessionConfigFiles() (map[string]string, error) {
+	home, _ := os.UserHomeDir()
+	prefix := defaultConfigFile + "-"
+	files, err := filepath.Glob(filepath.Join(home, defaultConfigDir, prefix+"*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session kubeconfigs %w", err)
+	}
+
+	sessions := make(map[string]string)
+	for _, file := range files {
+		if strings.HasSuffix(file, lockSuffix) || isRenamedLink(file) {
+			continue
+		}
+		sessions[strings.TrimPrefix(filepath.Base(file), prefix)] = file
+	}
+	return sessions, nil
+}
+
+// Find the contexts in any session matching the pattern
+func FindContexts(pattern string) ([]IndexEntry, error) {
+	index, err := ContextIndex()
+	if err != nil {
+		return nil, err
+	}
+	return FilterIndex(index, pattern), nil
+}
+
+// Filter index entries to those matching the pattern
+func FilterIndex(index []IndexEntry, pattern string) []IndexEntry {
+	matches := make([]IndexEntry, 0)
+	for _, entry := range index {
+		if entry.Matches(pattern) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
@@ -20,15 +20,13 @@
 package kubernetes
 
 import (
-	"context"
 	"fmt"
+	"path/filepath"
 	"sort"
-	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mproffitt/bmx/pkg/components/icons"
 	"github.com/pkg/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
@@ -55,29 +53,43 @@ func ContextDeleteCmd() tea.Cmd {
 // information about a context in a given kubeconfig
 // file.
 //
+// Name is always the real name of the context in the file and
+// should be used for all operations. DisplayName is derived from
+// it by the current naming strategy and is for display only.
+//
 // The struct implements `bubbles::list.DefaultItem` interface
 // and can be used directly in bubbletea lists
 type KubeContext struct {
 	Name             string
+	DisplayName      string
 	User             string
 	Host             string
 	Namespace        string
 	IsCurrentContext bool
-	fullname         string
+
+	// The kubeconfig file the context was loaded from. Contexts from
+	// any file other than the first in KUBECONFIG are read-only
+	Origin   string
+	ReadOnly bool
 }
 
 // Get the title of this list item
 func (k KubeContext) Title() string {
-	return k.Name
+	return k.DisplayName
 }
 
 // Get the description value of this list item
+//
+// Contexts from a shared layer show the file they came from
 func (k KubeContext) Description() string {
+	if k.ReadOnly {
+		return fmt.Sprintf("%s %c %s", k.Namespace, icons.Layers, filepath.Base(k.Origin))
+	}
 	return k.Namespace
 }
 
 // Get the value to fileter by
-func (k KubeContext) FilterValue() string { return k.Name }
+func (k KubeContext) FilterValue() string { return k.DisplayName }
 
 // Load contexts from a kubeconfig file
 //
@@ -94,64 +106,33 @@ func KubeContextList(shouldManage bool, filename string) ([]KubeContext, error)
 	return list, err
 }
 
-// Gets the fullname of a context
-//
-// Shortened names are used for display but for
-// certain actions, the full name of the context is
-// required.
-func GetFullName(name, filename string) string {
-	list, err := KubeContextList(true, filename)
-	if err == nil {
-		for _, c := range list {
-			if c.Name == name {
-				return c.fullname
-			}
-		}
-	}
-	return ""
-}
-
 // Use only for rest clients connecting to the cluster
 func buildConfigFromFlags(context, filename string) (*rest.Config, error) {
 	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		&clientcmd.ClientConfigLoadingRules{
-			ExplicitPath: filename,
+			Precedence: ConfigFiles(filename),
 		},
 		&clientcmd.ConfigOverrides{
 			CurrentContext: context,
 		}).ClientConfig()
 }
 
-// Use for modifying local files
-func getApiConfig(filename string) (*clientcmd.PathOptions, *api.Config, error) {
-	options := clientcmd.NewDefaultPathOptions()
-	options.GlobalFile = filename
-	options.EnvVar = ""
-	config, err := options.GetStartingConfig()
-	if err != nil {
-		return nil, nil, err
-	}
-	return options, config, nil
-}
-
 // Changes the configs current context to the name provided
+//
+// The context may come from any layer but the current context is
+// always written to the session file
 func SetCurrentContext(name, filename string) error {
-	// Sets the current kubeconfig context to the value selected
-	fullname := GetFullName(name, filename)
-	if fullname == "" {
-		return fmt.Errorf("context name %q does not exist in current config %q", name, filename)
-	}
-
-	options, config, err := getApiConfig(filename)
-	config.CurrentContext = fullname
-	if err == nil {
-		err = clientcmd.ModifyConfig(options, *config, true)
-	}
-	return err
+	return modifyLayered(filename, func(writable, merged *api.Config) error {
+		if _, ok := merged.Contexts[name]; !ok {
+			return fmt.Errorf("context name %q does not exist in current config %q", name, filename)
+		}
+		writable.CurrentContext = name
+		return nil
+	})
 }
 
 func GetCurrentContext(filename string) (string, error) {
-	_, config, err := getApiConfig(filename)
+	config, err := loadMerged(filename)
 	var current string
 	{
 		if err == nil {
@@ -162,80 +143,96 @@ func GetCurrentContext(filename string) (string, error) {
 }
 
 func SetNamespace(ctx, namespace, filename string) error {
-	// Sets the current kubeconfig context to the value selected
-	fullname := GetFullName(ctx, filename)
-	if fullname == "" {
-		return fmt.Errorf("context name %q does not exist in current config %q", ctx, filename)
-	}
+	return modifyLayered(filename, func(writable, merged *api.Config) error {
+		return setNamespace(ctx, namespace, filename, writable, merged)
+	})
+}
 
-	options, config, err := getApiConfig(filename)
-	config.Contexts[fullname].Namespace = namespace
-	if err == nil {
-		err = clientcmd.ModifyConfig(options, *config, true)
+// Set the namespace for a context
+//
+// Contexts from a shared layer are overridden by adding a copy of the
+// context to the session file. Only the context is copied, its user and
+// cluster are still read from the shared layer
+func setNamespace(ctx, namespace, filename string, writable, merged *api.Config) error {
+	context, ok := writable.Contexts[ctx]
+	if !ok {
+		shared, ok := merged.Contexts[ctx]
+		if !ok {
+			return fmt.Errorf("context name %q does not exist in current config %q", ctx, filename)
+		}
+		context = shared.DeepCopy()
+		context.LocationOfOrigin = ""
+		writable.Contexts[ctx] = context
 	}
-	return err
+	context.Namespace = namespace
+	return nil
 }
 
 func DeleteContext(ctx, filename string) error {
-	fullname := GetFullName(ctx, filename)
-	if fullname == "" {
-		return fmt.Errorf("context name %q does not exist in the current config %q", ctx, filename)
-	}
-	options, config, err := getApiConfig(filename)
-	user := config.Contexts[fullname].AuthInfo
-	cluster := config.Contexts[fullname].Cluster
+	return modifyLayered(filename, func(writable, merged *api.Config) error {
+		if err := checkWritable(ctx, filename, writable, merged); err != nil {
+			return err
+		}
+		deleteContext(writable, ctx)
+		return nil
+	})
+}
+
+// Remove a context and its user and cluster from the config
+func deleteContext(config *api.Config, name string) {
+	user := config.Contexts[name].AuthInfo
+	cluster := config.Contexts[name].Cluster
 	{
-		delete(config.AuthInfos, user)
-		// delete clusters
-		// applications such as teleport share the cluster information
-		// across multiple contexts. Only delete if it's the only instance using it
-		found := false
-		for name, context := range config.Contexts {
-			if name == fullname {
+		// applications such as teleport share the cluster and user
+		// information across multiple contexts. Only delete them if
+		// this is the only context using them
+		clusterFound, userFound := false, false
+		for other, context := range config.Contexts {
+			if other == name {
 				continue
 			}
-			if context.Cluster == cluster {
-				found = true
-			}
+			clusterFound = clusterFound || context.Cluster == cluster
+			userFound = userFound || context.AuthInfo == user
+		}
+		if !userFound {
+			delete(config.AuthInfos, user)
 		}
-		if !found {
+		if !clusterFound {
 			delete(config.Clusters, cluster)
 		}
 		// delete context
-		delete(config.Contexts, fullname)
+		delete(config.Contexts, name)
 	}
 	// unset current context if applicable
-	if config.CurrentContext == fullname {
+	if config.CurrentContext == name {
 		config.CurrentContext = ""
 	}
-	if err == nil {
-		err = clientcmd.ModifyConfig(options, *config, true)
-	}
-
-	return err
 }
 
 func listContexts(list *[]KubeContext, filename string) error {
-	options := clientcmd.NewDefaultPathOptions()
-	options.EnvVar = ""
-	options.GlobalFile = filename
-	config, err := options.GetStartingConfig()
+	config, err := loadMerged(filename)
 	if err != nil {
 		return errors.Wrap(err, "cannot get starting config")
 	}
+	writable := WritableConfig(filename)
 
 	contexts := make([]KubeContext, 0)
+	names := displayNames(config)
 
 	for name, ctx := range config.Contexts {
-		shortname := strings.Join(strings.Split(name, "-")[1:], "-")
-
+		var host string
+		if cluster, ok := config.Clusters[ctx.Cluster]; ok {
+			host = cluster.Server
+		}
 		kctx := KubeContext{
-			Name:             shortname,
+			Name:             name,
+			DisplayName:      names[name],
 			User:             ctx.AuthInfo,
-			Host:             config.Clusters[ctx.Cluster].Server,
+			Host:             host,
 			Namespace:        ctx.Namespace,
 			IsCurrentContext: name == config.CurrentContext,
-			fullname:         name,
+			Origin:           ctx.LocationOfOrigin,
+			ReadOnly:         ctx.LocationOfOrigin != writable,
 		}
 		if kctx.Namespace == "" {
 			kctx.Namespace = "default"
@@ -244,36 +241,12 @@ func listContexts(list *[]KubeContext, filename string) error {
 	}
 
 	sort.SliceStable(contexts, func(i, j int) bool {
-		return contexts[i].Name < contexts[j].Name
+		if contexts[i].DisplayName == contexts[j].DisplayName {
+			return contexts[i].Name < contexts[j].Name
+		}
+		return contexts[i].DisplayName < contexts[j].DisplayName
 	})
 	*list = make([]KubeContext, len(contexts))
 	copy(*list, contexts)
 	return nil
 }
-
-// Get all namespaces listed in the given context
-func GetNamespaces(ctx, filename string) ([]string, error) {
-	var namespaces []string
-	{
-		config, err := buildConfigFromFlags(ctx, filename)
-		if err != nil {
-			return nil, err
-		}
-		clientset, err := kubernetes.NewForConfig(config)
-		if err != nil {
-			return nil, err
-		}
-
-		list, err := clientset.CoreV1().
-			Namespaces().
-			List(context.Background(), metav1.ListOptions{})
-		if err != nil {
-			return nil, err
-		}
-
-		for _, v := range list.Items {
-			namespaces = append(namespaces, v.GetName())
-		}
-	}
-	return namespaces, nil
-}
@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Shared kubeconfig files added to each new session after its own file
+var defaultLayers []string
+
+// Set the shared kubeconfig files new sessions should include
+//
+// A leading `~/` in any path is expanded to the users home directory
+func SetDefaultLayers(layers []string) {
+	defaultLayers = make([]string, 0, len(layers))
+	for _, layer := range layers {
+		defaultLayers = append(defaultLayers, helpers.ExpandHome(layer))
+	}
+}
+
+// Get the shared kubeconfig files new sessions include by default
+func DefaultLayers() []string {
+	return slices.Clone(defaultLayers)
+}
+
+// Build the KUBECONFIG value for a session
+//
+// The session's own file always comes first. It is the only file bmx
+// writes to, and kubectl also writes changes such as the current
+// context to the first file in the list. The shared layers follow in
+// the order given and are treated as read-only.
+func SessionKubeconfig(sessionName string, layers []string) (string, error) {
+	configFile, err := CreateConfig(sessionName)
+	if err != nil {
+		return "", err
+	}
+	return JoinConfigFiles(append([]string{configFile}, layers...)...), nil
+}
+
+// Join kubeconfig files into a KUBECONFIG style list
+//
+// Empty entries and duplicates are removed
+func JoinConfigFiles(files ...string) string {
+	list := make([]string, 0, len(files))
+	for _, file := range files {
+		if file != "" && !slices.Contains(list, file) {
+			list = append(list, file)
+		}
+	}
+	return strings.Join(list, string(os.PathListSeparator))
+}
+
+// Split a KUBECONFIG style list into its files
+func ConfigFiles(kubeconfig string) []string {
+	files := make([]string, 0)
+	for _, file := range filepath.SplitList(kubeconfig) {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// Get the file changes are written to
+//
+// This is always the first file in the list
+func WritableConfig(kubeconfig string) string {
+	if files := ConfigFiles(kubeconfig); len(files) > 0 {
+		return files[0]
+	}
+	return ""
+}
+
+// Get the read-only layers in a KUBECONFIG style list
+//
+// Returns nil if kubeconfig is empty
+func ConfigLayers(kubeconfig string) []string {
+	files := ConfigFiles(kubeconfig)
+	if len(files) == 0 {
+		return nil
+	}
+	return files[1:]
+}
+
+// Load all files in a KUBECONFIG style list, merged the same way
+// kubectl merges them.
+//
+// Each entry records the file it came from in LocationOfOrigin.
+// Files that don't exist are skipped.
+func loadMerged(kubeconfig string) (*api.Config, error) {
+	rules := clientcmd.ClientConfigLoadingRules{
+		Precedence: ConfigFiles(kubeconfig),
+	}
+	config, err := rules.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig %q %w", kubeconfig, err)
+	}
+	return config, nil
+}
+
+// Apply a modification to the writable file of a layered kubeconfig
+//
+// `modify` receives both the writable config, which is written back
+// once it returns, and the merged view of all layers for looking up
+// entries that live in the shared files.
+func modifyLayered(kubeconfig string, modify func(writable, merged *api.Config) error) error {
+	return modifyConfig(WritableConfig(kubeconfig), func(writable *api.Config) error {
+		merged, err := loadMerged(kubeconfig)
+		if err != nil {
+			return err
+		}
+		return modify(writable, merged)
+	})
+}
+
+// Check a context can be changed or removed in the writable file
+func checkWritable(name, kubeconfig string, writable, merged *api.Config) error {
+	if _, ok := writable.Contexts[name]; ok {
+		return nil
+	}
+	if _, ok := merged.Contexts[name]; ok {
+		return readOnlyError(name, merged)
+	}
+	return fmt.Errorf("context name %q does not exist in the current config %q", name, kubeconfig)
+}
+
+// Error for a context that only exists in a read-only layer
+func readOnlyError(name string, merged *api.Config) error {
+	return fmt.Errorf("context %q is provided by the shared kubeconfig %q and cannot be changed here",
+		name, merged.Contexts[name].LocationOfOrigin)
+}
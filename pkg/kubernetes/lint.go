@@ -0,0 +1,284 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// LintCheck identifies the kind of problem found in a kubeconfig
+type LintCheck string
+
+const (
+	// A user that no context refers to
+	UnusedUser LintCheck = "unused-user"
+
+	// A cluster that no context refers to
+	UnusedCluster LintCheck = "unused-cluster"
+
+	// A cluster with the same settings as another under a different name
+	DuplicateServer LintCheck = "duplicate-server"
+
+	// A certificate, key or token file that does not exist
+	MissingFile LintCheck = "missing-file"
+
+	// current-context names a context that does not exist
+	MissingCurrentContext LintCheck = "missing-current-context"
+
+	// A context that refers to a cluster or user that does not exist
+	MissingReference LintCheck = "missing-reference"
+)
+
+// Issue is a single problem found in a kubeconfig file
+type Issue struct {
+	File    string
+	Check   LintCheck
+	Name    string
+	Message string
+
+	// Can the problem be fixed without losing anything in use
+	Fixable bool
+
+	// Has the problem been fixed
+	Fixed bool
+
+	fix func(*api.Config)
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s %s: %s", i.Check, i.Name, i.Message)
+}
+
+// Check the writable file of a kubeconfig for problems
+//
+// `kubeconfig` may be a KUBECONFIG style list. Only the first file is
+// checked but contexts, clusters and users in the other files are
+// taken into account, so a user in the session file that is only used
+// by a context in a shared layer is not reported as unused.
+func Lint(kubeconfig string) ([]Issue, error) {
+	filename := WritableConfig(kubeconfig)
+	config, err := loadConfig(filename)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := loadMerged(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return lintConfig(filename, config, merged), nil
+}
+
+// Fix the problems in the writable file of a kubeconfig
+//
+// Only problems that can be fixed safely are changed. All problems
+// found are returned with those that were fixed marked as such.
+func Fix(kubeconfig string) ([]Issue, error) {
+	filename := WritableConfig(kubeconfig)
+	var issues []Issue
+	err := modifyLayered(kubeconfig, func(writable, merged *api.Config) error {
+		issues = lintConfig(filename, writable, merged)
+		for i := range issues {
+			if issues[i].Fixable {
+				issues[i].fix(writable)
+				issues[i].Fixed = true
+			}
+		}
+		return nil
+	})
+	return issues, err
+}
+
+// Find the problems in a config
+//
+// `merged` is the view of every file in the kubeconfig and is used to
+// resolve references to entries that live in other files. Each fixable
+// issue carries the change that fixes it, to be applied to `config`
+func lintConfig(filename string, config, merged *api.Config) []Issue {
+	issues := make([]Issue, 0)
+	add := func(check LintCheck, name, message string, fix func(*api.Config)) {
+		issues = append(issues, Issue{
+			File:    filename,
+			Check:   check,
+			Name:    name,
+			Message: message,
+			Fixable: fix != nil,
+			fix:     fix,
+		})
+	}
+
+	// contexts from every file that may refer to entries in this one
+	contexts := make(map[string]*api.Context)
+	for name, ctx := range merged.Contexts {
+		if ctx.LocationOfOrigin != filename {
+			contexts[name] = ctx
+		}
+	}
+	for name, ctx := range config.Contexts {
+		contexts[name] = ctx
+	}
+	clusterUsers := func(cluster string, outside bool) []string {
+		names := make([]string, 0)
+		for name, ctx := range contexts {
+			if ctx.Cluster == cluster && (!outside || ctx.LocationOfOrigin != filename) {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+
+	if current := config.CurrentContext; current != "" {
+		_, local := config.Contexts[current]
+		_, shared := merged.Contexts[current]
+		if !local && !shared {
+			add(MissingCurrentContext, current, "current-context refers to a context that does not exist",
+				func(c *api.Config) { c.CurrentContext = "" })
+		}
+	}
+
+	for name, ctx := range config.Contexts {
+		if _, ok := config.Clusters[ctx.Cluster]; !ok && merged.Clusters[ctx.Cluster] == nil {
+			add(MissingReference, name, fmt.Sprintf("refers to cluster %q which does not exist", ctx.Cluster), nil)
+		}
+		if _, ok := config.AuthInfos[ctx.AuthInfo]; !ok && merged.AuthInfos[ctx.AuthInfo] == nil {
+			add(MissingReference, name, fmt.Sprintf("refers to user %q which does not exist", ctx.AuthInfo), nil)
+		}
+	}
+
+	unused := make(map[string]bool)
+	for name := range config.AuthInfos {
+		used := false
+		for _, ctx := range contexts {
+			used = used || ctx.AuthInfo == name
+		}
+		if !used {
+			add(UnusedUser, name, "not used by any context",
+				func(c *api.Config) { delete(c.AuthInfos, name) })
+		}
+	}
+	for name := range config.Clusters {
+		if len(clusterUsers(name, false)) == 0 {
+			unused[name] = true
+			add(UnusedCluster, name, "not used by any context",
+				func(c *api.Config) { delete(c.Clusters, name) })
+		}
+	}
+
+	// Clusters pointing at the same server. Identical clusters are
+	// merged in to the first by name, unless a context in another
+	// file refers to the one that would be removed
+	servers := make(map[string][]string)
+	for name, cluster := range config.Clusters {
+		if cluster.Server != "" && !unused[name] {
+			servers[cluster.Server] = append(servers[cluster.Server], name)
+		}
+	}
+	for server, names := range servers {
+		if len(names) < 2 {
+			continue
+		}
+		slices.Sort(names)
+		keep := names[0]
+		for _, name := range names[1:] {
+			message := fmt.Sprintf("has the same server %q as cluster %q", server, keep)
+			if !clustersEqual(config.Clusters[keep], config.Clusters[name]) {
+				add(DuplicateServer, name, message+" but different settings", nil)
+				continue
+			}
+			if outside := clusterUsers(name, true); len(outside) > 0 {
+				add(DuplicateServer, name, message+fmt.Sprintf(". used by %q in another file", outside[0]), nil)
+				continue
+			}
+			add(DuplicateServer, name, message, func(c *api.Config) {
+				for _, ctx := range c.Contexts {
+					if ctx.Cluster == name {
+						ctx.Cluster = keep
+					}
+				}
+				delete(c.Clusters, name)
+			})
+		}
+	}
+
+	dir := filepath.Dir(filename)
+	for name, cluster := range config.Clusters {
+		if missingFile(dir, cluster.CertificateAuthority) {
+			message := fmt.Sprintf("certificate-authority %q does not exist", cluster.CertificateAuthority)
+			var fix func(*api.Config)
+			if len(cluster.CertificateAuthorityData) > 0 {
+				fix = func(c *api.Config) { c.Clusters[name].CertificateAuthority = "" }
+			}
+			add(MissingFile, name, message, fix)
+		}
+	}
+	for name, user := range config.AuthInfos {
+		files := []struct {
+			field, path string
+			hasData     bool
+			clear       func(*api.AuthInfo)
+		}{
+			{"client-certificate", user.ClientCertificate, len(user.ClientCertificateData) > 0,
+				func(u *api.AuthInfo) { u.ClientCertificate = "" }},
+			{"client-key", user.ClientKey, len(user.ClientKeyData) > 0,
+				func(u *api.AuthInfo) { u.ClientKey = "" }},
+			{"tokenFile", user.TokenFile, user.Token != "",
+				func(u *api.AuthInfo) { u.TokenFile = "" }},
+		}
+		for _, f := range files {
+			if !missingFile(dir, f.path) {
+				continue
+			}
+			var fix func(*api.Config)
+			if f.hasData {
+				clear := f.clear
+				fix = func(c *api.Config) { clear(c.AuthInfos[name]) }
+			}
+			add(MissingFile, name, fmt.Sprintf("%s %q does not exist", f.field, f.path), fix)
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Check == issues[j].Check {
+			return issues[i].Name < issues[j].Name
+		}
+		return issues[i].Check < issues[j].Check
+	})
+	return issues
+}
+
+// Is path set but missing from disk
+//
+// Relative paths are resolved against the directory of the
+// kubeconfig, the same as kubectl
+func missingFile(dir, path string) bool {
+	if path == "" {
+		return false
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	_, err := os.Stat(path)
+	return os.IsNotExist(err)
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	lockSuffix   = ".lock"
+	lockTimeout  = 5 * time.Second
+	lockInterval = 50 * time.Millisecond
+)
+
+// ErrConfigLocked is returned when the lock for a kubeconfig file
+// cannot be acquired before the lock timeout expires
+var ErrConfigLocked = errors.New("kubeconfig is locked by another process")
+
+// Lock a kubeconfig file for writing
+//
+// This uses the same `<filename>.lock` convention as client-go so
+// kubectl, tsh and bmx never write the same file at the same time.
+// Where client-go fails immediately if the lock is held, this will
+// retry until `lockTimeout` has passed.
+//
+// The returned function releases the lock
+func lockConfig(filename string) (func(), error) {
+	lockfile := filename + lockSuffix
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockfile, os.O_CREATE|os.O_EXCL, 0)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(lockfile) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock kubeconfig %q %w", filename, err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w. remove %q if this is stale", ErrConfigLocked, lockfile)
+		}
+		time.Sleep(lockInterval)
+	}
+}
+
+// Lock multiple kubeconfig files
+//
+// Files are always locked in sorted order to prevent two bmx
+// processes deadlocking against each other.
+func lockConfigs(filenames ...string) (func(), error) {
+	files := slices.Clone(filenames)
+	slices.Sort(files)
+	files = slices.Compact(files)
+
+	unlocks := make([]func(), 0, len(files))
+	unlock := func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+	for _, file := range files {
+		u, err := lockConfig(file)
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+		unlocks = append(unlocks, u)
+	}
+	return unlock, nil
+}
+
+// Load a kubeconfig file directly from disk
+//
+// A file that does not exist is treated as an empty config
+func loadConfig(filename string) (*api.Config, error) {
+	config, err := clientcmd.LoadFromFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return api.NewConfig(), nil
+		}
+		return nil, fmt.Errorf("failed to load kubeconfig %q %w", filename, err)
+	}
+	return config, nil
+}
+
+// Write the config to file atomically
+//
+// The caller is expected to hold the lock for the file
+func writeConfig(config *api.Config, filename string) error {
+	content, err := clientcmd.Write(*config)
+	if err != nil {
+		return fmt.Errorf("failed to serialise kubeconfig %q %w", filename, err)
+	}
+	return writeFileAtomic(filename, content, 0600)
+}
+
+// Write content to a temporary file alongside the destination and
+// rename it into place so readers never see a partially written file.
+//
+// Symlinks are resolved first so the link itself is preserved, and
+// the permissions of an existing file are kept.
+func writeFileAtomic(filename string, content []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = target
+	}
+	if info, err := os.Stat(filename); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %q %w", filename, err)
+	}
+	cleanup := func(err error) error {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write %q %w", filename, err)
+	}
+
+	if _, err := tmp.Write(content); err != nil {
+		return cleanup(err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return cleanup(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return cleanup(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return cleanup(err)
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace %q %w", filename, err)
+	}
+	return nil
+}
+
+// Apply a modification to a kubeconfig file
+//
+// The file is locked, loaded, passed to `modify` and, if that
+// succeeds, written back atomically before the lock is released.
+func modifyConfig(filename string, modify func(*api.Config) error) error {
+	unlock, err := lockConfig(filename)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	config, err := loadConfig(filename)
+	if err != nil {
+		return err
+	}
+	if err := modify(config); err != nil {
+		return err
+	}
+	return writeConfig(config, filename)
+}
+
+// snapshot holds the raw contents of a file so it can be restored
+// if a later step in a multi-file operation fails
+type snapshot struct {
+	filename string
+	content  []byte
+	exists   bool
+}
+
+func takeSnapshot(filename string) (*snapshot, error) {
+	s := snapshot{filename: filename}
+	content, err := os.ReadFile(filename)
+	switch {
+	case err == nil:
+		s.content = content
+		s.exists = true
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read %q %w", filename, err)
+	}
+	return &s, nil
+}
+
+// Restore the file to the state it was in when the snapshot was taken
+func (s *snapshot) restore() error {
+	if !s.exists {
+		err := os.Remove(s.filename)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return writeFileAtomic(s.filename, s.content, 0600)
+}
@@ -20,38 +20,173 @@
 package kubernetes
 
 import (
-	"k8s.io/client-go/tools/clientcmd"
+	"fmt"
+
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 // Move context between sessions
-
+//
+// Both files are locked for the duration of the move. The destination
+// is written first and if removing the context from the origin then
+// fails, the destination is restored to its original state so the
+// context is never left duplicated or lost.
 func MoveContext(name, origfile, newfile string) error {
-	if origfile == newfile {
-		return nil
-	}
-	fullname := GetFullName(name, origfile)
-	_, originalConfig, err := getApiConfig(origfile)
+	results, err := transferContexts([]string{name}, origfile, newfile, true)
 	if err != nil {
 		return err
 	}
+	return results[name]
+}
 
-	_, nc, err := getApiConfig(newfile)
+// Copy context between sessions
+//
+// The context, its user and its cluster are copied to the new file
+// leaving the original in place
+func CopyContext(name, origfile, newfile string) error {
+	results, err := transferContexts([]string{name}, origfile, newfile, false)
 	if err != nil {
 		return err
 	}
+	return results[name]
+}
+
+// Move multiple contexts between sessions in a single operation
+func MoveContexts(names []string, origfile, newfile string) Results {
+	results, err := transferContexts(names, origfile, newfile, true)
+	if err != nil {
+		return failAll(names, err)
+	}
+	return results
+}
+
+// Copy multiple contexts between sessions in a single operation
+func CopyContexts(names []string, origfile, newfile string) Results {
+	results, err := transferContexts(names, origfile, newfile, false)
+	if err != nil {
+		return failAll(names, err)
+	}
+	return results
+}
 
-	newConfig := *nc
+// Copy or move contexts from one file to another
+//
+// Contexts which already exist in the destination are not replaced and
+// are reported as failed. Clusters and users are renamed rather than
+// replaced when the destination holds a different one with the same
+// name.
+//
+// The returned error is only set if the operation as a whole could not
+// be carried out. Individual failures are recorded in the results
+func transferContexts(names []string, origfile, newfile string, move bool) (Results, error) {
+	// contexts from shared layers are never transferred so only the
+	// session files are involved
+	layers := origfile
+	origfile, newfile = WritableConfig(origfile), WritableConfig(newfile)
 
-	context := *originalConfig.Contexts[fullname]
-	authinfo := *originalConfig.AuthInfos[context.AuthInfo]
-	cluster := *originalConfig.Clusters[context.Cluster]
-	newConfig.Contexts[fullname] = &context
-	newConfig.AuthInfos[context.AuthInfo] = &authinfo
-	newConfig.Clusters[context.Cluster] = &cluster
+	results := make(Results)
+	if origfile == newfile {
+		for _, name := range names {
+			results[name] = nil
+		}
+		return results, nil
+	}
 
-	err = clientcmd.WriteToFile(newConfig, newfile)
+	unlock, err := lockConfigs(origfile, newfile)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer unlock()
+
+	originalConfig, err := loadConfig(origfile)
+	if err != nil {
+		return nil, err
 	}
-	return DeleteContext(name, origfile)
+
+	merged, err := loadMerged(layers)
+	if err != nil {
+		return nil, err
+	}
+
+	newConfig, err := loadConfig(newfile)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		transferred = make([]string, 0)
+		clusters    = make(map[string]string)
+		users       = make(map[string]string)
+	)
+	for _, name := range names {
+		if results[name] = checkWritable(name, layers, originalConfig, merged); results[name] != nil {
+			continue
+		}
+		if _, ok := newConfig.Contexts[name]; ok {
+			results[name] = fmt.Errorf("context %q already exists in %q", name, newfile)
+			continue
+		}
+		if results[name] = copyContext(originalConfig, newConfig, name, clusters, users); results[name] == nil {
+			transferred = append(transferred, name)
+		}
+	}
+	if len(transferred) == 0 {
+		return results, nil
+	}
+
+	backup, err := takeSnapshot(newfile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = writeConfig(newConfig, newfile); err != nil {
+		return nil, err
+	}
+
+	if !move {
+		return results, nil
+	}
+
+	for _, name := range transferred {
+		deleteContext(originalConfig, name)
+	}
+	if err = writeConfig(originalConfig, origfile); err != nil {
+		if rerr := backup.restore(); rerr != nil {
+			return nil, fmt.Errorf("failed to remove contexts from %q (%w) and failed to roll back %q %v",
+				origfile, err, newfile, rerr)
+		}
+		return nil, fmt.Errorf("failed to move contexts. changes have been rolled back %w", err)
+	}
+	return results, nil
+}
+
+// Copy a context along with its user and cluster from one config
+// into another
+//
+// A cluster or user already in the destination under the same name is
+// reused if it is identical, otherwise it is copied under a new name
+// the same way imports are. `clusters` and `users` record the names
+// chosen so entries shared by several contexts are only copied once
+func copyContext(from, to *api.Config, name string, clusters, users map[string]string) error {
+	context, ok := from.Contexts[name]
+	if !ok {
+		return fmt.Errorf("context %q not found", name)
+	}
+	authinfo, ok := from.AuthInfos[context.AuthInfo]
+	if !ok {
+		return fmt.Errorf("user %q for context %q not found", context.AuthInfo, name)
+	}
+	cluster, ok := from.Clusters[context.Cluster]
+	if !ok {
+		return fmt.Errorf("cluster %q for context %q not found", context.Cluster, name)
+	}
+
+	copied := context.DeepCopy()
+	copied.Cluster, _ = resolveImportName(context.Cluster, cluster, to.Clusters, clusters, clustersEqual)
+	copied.AuthInfo, _ = resolveImportName(context.AuthInfo, authinfo, to.AuthInfos, users, usersEqual)
+
+	to.Contexts[name] = copied
+	to.AuthInfos[copied.AuthInfo] = authinfo.DeepCopy()
+	to.Clusters[copied.Cluster] = cluster.DeepCopy()
+	return nil
 }
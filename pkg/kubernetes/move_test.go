@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Write a kubeconfig holding the given contexts
+//
+// Each context refers to a cluster and user named in the maps
+func writeKubeconfig(t *testing.T, contexts map[string][2]string, servers map[string]string, tokens map[string]string) string {
+	t.Helper()
+	config := api.NewConfig()
+	for context, refs := range contexts {
+		config.Contexts[context] = &api.Context{Cluster: refs[0], AuthInfo: refs[1]}
+	}
+	for cluster, server := range servers {
+		config.Clusters[cluster] = &api.Cluster{Server: server}
+	}
+	for user, token := range tokens {
+		config.AuthInfos[user] = &api.AuthInfo{Token: token}
+	}
+	filename := filepath.Join(t.TempDir(), "config")
+	if err := clientcmd.WriteToFile(*config, filename); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func readKubeconfig(t *testing.T, filename string) *api.Config {
+	t.Helper()
+	config, err := clientcmd.LoadFromFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestCopyContextsRenamesConflicts(t *testing.T) {
+	from := writeKubeconfig(t,
+		map[string][2]string{"one": {"kind", "admin"}, "two": {"kind", "admin"}},
+		map[string]string{"kind": "https://127.0.0.1:6443"},
+		map[string]string{"admin": "secret"})
+	to := writeKubeconfig(t,
+		map[string][2]string{"existing": {"kind", "admin"}},
+		map[string]string{"kind": "https://127.0.0.1:7443"},
+		map[string]string{"admin": "secret"})
+
+	results := CopyContexts([]string{"one", "two"}, from, to)
+	if failed := results.Failed(); len(failed) != 0 {
+		t.Fatalf("failed to copy %v: %v", failed, results)
+	}
+
+	config := readKubeconfig(t, to)
+	if server := config.Clusters["kind"].Server; server != "https://127.0.0.1:7443" {
+		t.Errorf("existing cluster server = %q, want it unchanged", server)
+	}
+	if config.Contexts["existing"].Cluster != "kind" {
+		t.Errorf("existing context cluster = %q, want kind", config.Contexts["existing"].Cluster)
+	}
+	for _, name := range []string{"one", "two"} {
+		context := config.Contexts[name]
+		if context == nil {
+			t.Fatalf("context %q was not copied", name)
+		}
+		if context.Cluster != "kind-1" {
+			t.Errorf("context %q cluster = %q, want kind-1", name, context.Cluster)
+		}
+		if context.AuthInfo != "admin" {
+			t.Errorf("context %q user = %q, want the identical user to be shared", name, context.AuthInfo)
+		}
+	}
+	if server := config.Clusters["kind-1"].Server; server != "https://127.0.0.1:6443" {
+		t.Errorf("copied cluster server = %q, want https://127.0.0.1:6443", server)
+	}
+	if len(config.Clusters) != 2 || len(config.AuthInfos) != 1 {
+		t.Errorf("got %d clusters and %d users, want 2 and 1", len(config.Clusters), len(config.AuthInfos))
+	}
+}
+
+func TestMoveContextReportsExistingContext(t *testing.T) {
+	from := writeKubeconfig(t,
+		map[string][2]string{"kind": {"kind", "admin"}},
+		map[string]string{"kind": "https://127.0.0.1:6443"},
+		map[string]string{"admin": "secret"})
+	to := writeKubeconfig(t,
+		map[string][2]string{"kind": {"other", "other"}},
+		map[string]string{"other": "https://127.0.0.1:7443"},
+		map[string]string{"other": "token"})
+
+	if err := MoveContext("kind", from, to); err == nil {
+		t.Fatal("expected moving over an existing context to fail")
+	}
+
+	if context := readKubeconfig(t, to).Contexts["kind"]; context.Cluster != "other" {
+		t.Errorf("destination context cluster = %q, want it unchanged", context.Cluster)
+	}
+	if _, ok := readKubeconfig(t, from).Contexts["kind"]; !ok {
+		t.Error("context was removed from the origin")
+	}
+}
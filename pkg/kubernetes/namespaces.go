@@ -0,0 +1,261 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	namespaceCacheFile  = "namespaces.yaml"
+	namespaceTimeout    = 5 * time.Second
+	maxRecentNamespaces = 5
+
+	// NamespaceMaxAge is how long cached namespaces are used
+	// before they are refreshed in the background
+	NamespaceMaxAge = 5 * time.Minute
+)
+
+// ErrNamespacesForbidden is returned when RBAC does not permit
+// listing namespaces in the cluster
+var ErrNamespacesForbidden = errors.New("not permitted to list namespaces")
+
+// NewClientset creates the client used to talk to the cluster
+// for the given context.
+//
+// This can be replaced to use a fake clientset
+var NewClientset = func(context, filename string) (kubernetes.Interface, error) {
+	config, err := buildConfigFromFlags(context, filename)
+	if err != nil {
+		return nil, err
+	}
+	config.Timeout = namespaceTimeout
+	return kubernetes.NewForConfig(config)
+}
+
+// NamespacesMsg is sent when the namespaces for a context have
+// been refreshed in the background
+type NamespacesMsg struct {
+	Context    string
+	Kubeconfig string
+	Entry      NamespaceEntry
+	Err        error
+}
+
+// NamespaceEntry holds the cached namespaces for a single context
+//
+// Entries are kept per cluster as well as per context so contexts
+// with the same name in different kubeconfigs, such as `kind-kind`,
+// do not share namespaces
+type NamespaceEntry struct {
+	Namespaces []string  `yaml:"namespaces,omitempty"`
+	Recent     []string  `yaml:"recent,omitempty"`
+	Forbidden  bool      `yaml:"forbidden,omitempty"`
+	Updated    time.Time `yaml:"updated,omitempty"`
+}
+
+// Get all known namespaces with the most recently used first
+func (e NamespaceEntry) All() []string {
+	all := slices.Clone(e.Recent)
+	for _, ns := range e.Namespaces {
+		if !slices.Contains(all, ns) {
+			all = append(all, ns)
+		}
+	}
+	return all
+}
+
+// Has the entry been cached for longer than NamespaceMaxAge
+func (e NamespaceEntry) Stale() bool {
+	return time.Since(e.Updated) > NamespaceMaxAge
+}
+
+// Get all namespaces listed in the given context
+func GetNamespaces(ctx, filename string) ([]string, error) {
+	client, err := NewClientset(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), namespaceTimeout)
+	defer cancel()
+	return ListNamespaces(c, client)
+}
+
+// List the namespaces in a cluster in sorted order
+//
+// If the client is not permitted to list namespaces, the returned
+// error wraps ErrNamespacesForbidden
+func ListNamespaces(ctx context.Context, client kubernetes.Interface) ([]string, error) {
+	list, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if apierrors.IsForbidden(err) {
+			return nil, fmt.Errorf("%w %w", ErrNamespacesForbidden, err)
+		}
+		return nil, err
+	}
+
+	namespaces := make([]string, 0, len(list.Items))
+	for _, v := range list.Items {
+		namespaces = append(namespaces, v.GetName())
+	}
+	slices.Sort(namespaces)
+	return namespaces, nil
+}
+
+// Get the cached namespaces for a context in the given kubeconfig
+func CachedNamespaces(context, filename string) NamespaceEntry {
+	cache, err := loadNamespaceCache(namespaceCachePath())
+	if err != nil {
+		return NamespaceEntry{}
+	}
+	return cache[namespaceKey(context, filename)]
+}
+
+// Refresh the namespaces for a context in the background
+//
+// The result is written to the cache and returned as a NamespacesMsg.
+// If listing is forbidden, the entry is marked as such so callers
+// can fall back to free text entry.
+func RefreshNamespacesCmd(context, filename string) tea.Cmd {
+	return func() tea.Msg {
+		msg := NamespacesMsg{Context: context, Kubeconfig: filename}
+		namespaces, err := GetNamespaces(context, filename)
+		forbidden := errors.Is(err, ErrNamespacesForbidden)
+		if err != nil && !forbidden {
+			msg.Entry, msg.Err = CachedNamespaces(context, filename), err
+			return msg
+		}
+
+		key := namespaceKey(context, filename)
+		msg.Entry, msg.Err = updateNamespaceCache(namespaceCachePath(), key, func(e *NamespaceEntry) {
+			e.Forbidden = forbidden
+			if !forbidden {
+				e.Namespaces = namespaces
+			}
+			e.Updated = time.Now()
+		})
+		return msg
+	}
+}
+
+// Record a namespace as recently used for a context in the
+// given kubeconfig
+func RecordNamespace(context, filename, namespace string) error {
+	key := namespaceKey(context, filename)
+	_, err := updateNamespaceCache(namespaceCachePath(), key, func(e *NamespaceEntry) {
+		e.Recent = slices.DeleteFunc(e.Recent, func(ns string) bool {
+			return ns == namespace
+		})
+		e.Recent = append([]string{namespace}, e.Recent...)
+		if len(e.Recent) > maxRecentNamespaces {
+			e.Recent = e.Recent[:maxRecentNamespaces]
+		}
+	})
+	return err
+}
+
+// The key a context is cached under
+//
+// This is the context name and the server of its cluster. The
+// name alone is used if the cluster cannot be found
+func namespaceKey(context, filename string) string {
+	config, err := loadMerged(filename)
+	if err != nil {
+		return context
+	}
+	c, ok := config.Contexts[context]
+	if !ok {
+		return context
+	}
+	cluster, ok := config.Clusters[c.Cluster]
+	if !ok || cluster.Server == "" {
+		return context
+	}
+	return context + "@" + cluster.Server
+}
+
+func namespaceCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, helpers.ExecutableName(), namespaceCacheFile)
+}
+
+func loadNamespaceCache(filename string) (map[string]NamespaceEntry, error) {
+	cache := make(map[string]NamespaceEntry)
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(content, &cache); err != nil {
+		return nil, fmt.Errorf("failed to read namespace cache %q %w", filename, err)
+	}
+	return cache, nil
+}
+
+// Apply a change to a single entry in the namespace cache
+//
+// The cache file is locked while the change is made so concurrent
+// refreshes from multiple bmx processes don't lose entries
+func updateNamespaceCache(filename, key string, update func(*NamespaceEntry)) (NamespaceEntry, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0750); err != nil {
+		return NamespaceEntry{}, fmt.Errorf("failed to create cache directory %w", err)
+	}
+
+	unlock, err := lockConfig(filename)
+	if err != nil {
+		return NamespaceEntry{}, err
+	}
+	defer unlock()
+
+	cache, err := loadNamespaceCache(filename)
+	if err != nil {
+		// a corrupt cache is rebuilt rather than blocking the user
+		cache = make(map[string]NamespaceEntry)
+	}
+
+	entry := cache[key]
+	update(&entry)
+	cache[key] = entry
+
+	content, err := yaml.Marshal(cache)
+	if err != nil {
+		return entry, err
+	}
+	return entry, writeFileAtomic(filename, content, 0600)
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func namespaceObjects(names ...string) []runtime.Object {
+	objects := make([]runtime.Object, 0, len(names))
+	for _, name := range names {
+		objects = append(objects, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		})
+	}
+	return objects
+}
+
+// Use a fake clientset per kubeconfig and keep the cache in a
+// temporary directory
+func useFakeClientsets(t *testing.T, clients map[string]kubernetes.Interface) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	previous := NewClientset
+	t.Cleanup(func() { NewClientset = previous })
+	NewClientset = func(context, filename string) (kubernetes.Interface, error) {
+		client, ok := clients[filename]
+		if !ok {
+			return nil, errors.New("no client for " + filename)
+		}
+		return client, nil
+	}
+}
+
+func TestRefreshNamespacesSeparatesClusters(t *testing.T) {
+	first := writeKubeconfig(t,
+		map[string][2]string{"kind-kind": {"cluster", "admin"}},
+		map[string]string{"cluster": "https://127.0.0.1:6443"},
+		map[string]string{"admin": "secret"})
+	second := writeKubeconfig(t,
+		map[string][2]string{"kind-kind": {"cluster", "admin"}},
+		map[string]string{"cluster": "https://127.0.0.1:7443"},
+		map[string]string{"admin": "secret"})
+	useFakeClientsets(t, map[string]kubernetes.Interface{
+		first:  fake.NewSimpleClientset(namespaceObjects("default", "one")...),
+		second: fake.NewSimpleClientset(namespaceObjects("default", "two")...),
+	})
+
+	for _, filename := range []string{first, second} {
+		msg := RefreshNamespacesCmd("kind-kind", filename)().(NamespacesMsg)
+		if msg.Err != nil {
+			t.Fatalf("refresh %q: %v", filename, msg.Err)
+		}
+		if msg.Kubeconfig != filename {
+			t.Errorf("message kubeconfig = %q, want %q", msg.Kubeconfig, filename)
+		}
+	}
+
+	tests := []struct {
+		filename string
+		want     []string
+	}{
+		{first, []string{"default", "one"}},
+		{second, []string{"default", "two"}},
+	}
+	for _, tt := range tests {
+		got := CachedNamespaces("kind-kind", tt.filename).Namespaces
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("cached namespaces for %q = %v, want %v", tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestRefreshNamespacesForbidden(t *testing.T) {
+	filename := writeKubeconfig(t,
+		map[string][2]string{"prod": {"cluster", "admin"}},
+		map[string]string{"cluster": "https://prod.example.com"},
+		map[string]string{"admin": "secret"})
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("list", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", errors.New("rbac"))
+	})
+	useFakeClientsets(t, map[string]kubernetes.Interface{filename: client})
+
+	msg := RefreshNamespacesCmd("prod", filename)().(NamespacesMsg)
+	if msg.Err != nil {
+		t.Fatalf("refresh: %v", msg.Err)
+	}
+	if !msg.Entry.Forbidden {
+		t.Error("entry is not marked as forbidden")
+	}
+	if !CachedNamespaces("prod", filename).Forbidden {
+		t.Error("cached entry is not marked as forbidden")
+	}
+}
+
+func TestRefreshNamespacesKeepsCacheOnError(t *testing.T) {
+	filename := writeKubeconfig(t,
+		map[string][2]string{"dev": {"cluster", "admin"}},
+		map[string]string{"cluster": "https://dev.example.com"},
+		map[string]string{"admin": "secret"})
+	client := fake.NewSimpleClientset(namespaceObjects("default")...)
+	useFakeClientsets(t, map[string]kubernetes.Interface{filename: client})
+
+	if msg := RefreshNamespacesCmd("dev", filename)().(NamespacesMsg); msg.Err != nil {
+		t.Fatalf("refresh: %v", msg.Err)
+	}
+	client.PrependReactor("list", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	msg := RefreshNamespacesCmd("dev", filename)().(NamespacesMsg)
+	if msg.Err == nil {
+		t.Fatal("expected an error")
+	}
+	if !slices.Equal(msg.Entry.Namespaces, []string{"default"}) {
+		t.Errorf("entry namespaces = %v, want the cached [default]", msg.Entry.Namespaces)
+	}
+}
+
+func TestRecordNamespace(t *testing.T) {
+	first := writeKubeconfig(t,
+		map[string][2]string{"kind-kind": {"cluster", "admin"}},
+		map[string]string{"cluster": "https://127.0.0.1:6443"},
+		map[string]string{"admin": "secret"})
+	second := writeKubeconfig(t,
+		map[string][2]string{"kind-kind": {"cluster", "admin"}},
+		map[string]string{"cluster": "https://127.0.0.1:7443"},
+		map[string]string{"admin": "secret"})
+	useFakeClientsets(t, map[string]kubernetes.Interface{
+		first: fake.NewSimpleClientset(namespaceObjects("a", "b", "default")...),
+	})
+	if msg := RefreshNamespacesCmd("kind-kind", first)().(NamespacesMsg); msg.Err != nil {
+		t.Fatalf("refresh: %v", msg.Err)
+	}
+
+	for _, ns := range []string{"n1", "n2", "b", "n3", "n4", "n5", "b"} {
+		if err := RecordNamespace("kind-kind", first, ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entry := CachedNamespaces("kind-kind", first)
+	wantRecent := []string{"b", "n5", "n4", "n3", "n2"}
+	if !slices.Equal(entry.Recent, wantRecent) {
+		t.Errorf("recent = %v, want %v", entry.Recent, wantRecent)
+	}
+	wantAll := []string{"b", "n5", "n4", "n3", "n2", "a", "default"}
+	if got := entry.All(); !slices.Equal(got, wantAll) {
+		t.Errorf("all = %v, want %v", got, wantAll)
+	}
+
+	if recent := CachedNamespaces("kind-kind", second).Recent; len(recent) != 0 {
+		t.Errorf("recent namespaces leaked to another cluster: %v", recent)
+	}
+}
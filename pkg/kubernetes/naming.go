@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// NamingStrategy controls how context names are shown in the UI
+type NamingStrategy string
+
+const (
+	// Show the context name exactly as it is in the kubeconfig
+	NamingFull NamingStrategy = "full"
+
+	// Extract the display name from the context name with a regular
+	// expression. The `name` group is used if present, otherwise the
+	// first group, otherwise the whole match
+	NamingRegex NamingStrategy = "regex"
+
+	// Shorten names created by well known tools such as teleport,
+	// GKE and EKS. Any other name is shown in full
+	NamingProvider NamingStrategy = "provider"
+)
+
+type namer func(name string, config *api.Config) string
+
+var contextNamer namer = fullName
+
+var (
+	eksPattern = regexp.MustCompile(`^arn:aws[\w-]*:eks:[^:]+:\d+:cluster/(.+)$`)
+	gkePattern = regexp.MustCompile(`^gke_[^_]+_[^_]+_(.+)$`)
+)
+
+// Set the strategy used to derive display names for contexts
+//
+// `pattern` is only used by the regex strategy. An empty strategy
+// shows names in full.
+func SetNaming(strategy NamingStrategy, pattern string) error {
+	switch strategy {
+	case "", NamingFull:
+		contextNamer = fullName
+	case NamingProvider:
+		contextNamer = providerName
+	case NamingRegex:
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid context naming pattern %q %w", pattern, err)
+		}
+		contextNamer = regexName(re)
+	default:
+		return fmt.Errorf("unknown context naming strategy %q", strategy)
+	}
+	return nil
+}
+
+// Work out the display name of every context in the config
+//
+// If two contexts end up with the same display name, or a context's
+// display name would be empty, the full name is used instead so
+// every context can still be told apart.
+func displayNames(config *api.Config) map[string]string {
+	names := make(map[string]string, len(config.Contexts))
+	count := make(map[string]int)
+	for name := range config.Contexts {
+		display := contextNamer(name, config)
+		if display == "" {
+			display = name
+		}
+		names[name] = display
+		count[display]++
+	}
+
+	for name, display := range names {
+		if count[display] > 1 {
+			names[name] = name
+		}
+	}
+	return names
+}
+
+func fullName(name string, _ *api.Config) string {
+	return name
+}
+
+func regexName(re *regexp.Regexp) namer {
+	group := 1
+	if i := re.SubexpIndex("name"); i > 0 {
+		group = i
+	}
+	return func(name string, _ *api.Config) string {
+		matches := re.FindStringSubmatch(name)
+		switch {
+		case matches == nil:
+			return name
+		case len(matches) > group:
+			return matches[group]
+		}
+		return matches[0]
+	}
+}
+
+// kind names are left alone as they are short already and the
+// default cluster, `kind-kind`, would otherwise be shown as `kind`
+func providerName(name string, config *api.Config) string {
+	if cluster := teleportCluster(name, config); cluster != "" {
+		return cluster
+	}
+	for _, re := range []*regexp.Regexp{eksPattern, gkePattern} {
+		if matches := re.FindStringSubmatch(name); matches != nil {
+			return matches[1]
+		}
+	}
+	return name
+}
+
+// Get the kubernetes cluster name from a context created by `tsh`
+//
+// tsh writes the cluster name into the arguments of the exec plugin
+// it installs for each user, which is more reliable than trying to
+// split it out of the context name.
+func teleportCluster(name string, config *api.Config) string {
+	context, ok := config.Contexts[name]
+	if !ok {
+		return ""
+	}
+	user, ok := config.AuthInfos[context.AuthInfo]
+	if !ok || user.Exec == nil || filepath.Base(user.Exec.Command) != "tsh" {
+		return ""
+	}
+
+	args := user.Exec.Args
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--kube-cluster="); ok {
+			return value
+		}
+		if arg == "--kube-cluster" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
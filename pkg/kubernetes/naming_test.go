@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestDisplayNames(t *testing.T) {
+	config := api.NewConfig()
+	config.AuthInfos["teleport"] = &api.AuthInfo{Exec: &api.ExecConfig{
+		Command: "/usr/local/bin/tsh",
+		Args:    []string{"kube", "credentials", "--kube-cluster=prod-eu"},
+	}}
+	for _, name := range []string{
+		"kind-kind",
+		"gke_project_zone_prod",
+		"arn:aws:eks:eu-west-1:123456789012:cluster/payments",
+		"teleport.example.com-prod-eu",
+		"team-a-dev",
+		"team-b-dev",
+	} {
+		config.Contexts[name] = &api.Context{}
+	}
+	config.Contexts["teleport.example.com-prod-eu"].AuthInfo = "teleport"
+
+	tests := []struct {
+		name     string
+		strategy NamingStrategy
+		pattern  string
+		want     map[string]string
+	}{
+		{
+			name:     "default",
+			strategy: "",
+			want: map[string]string{
+				"kind-kind":             "kind-kind",
+				"gke_project_zone_prod": "gke_project_zone_prod",
+				"team-a-dev":            "team-a-dev",
+			},
+		},
+		{
+			name:     "provider",
+			strategy: NamingProvider,
+			want: map[string]string{
+				"kind-kind":             "kind-kind",
+				"gke_project_zone_prod": "prod",
+				"arn:aws:eks:eu-west-1:123456789012:cluster/payments": "payments",
+				"teleport.example.com-prod-eu":                        "prod-eu",
+			},
+		},
+		{
+			// both would be shown as `dev` so are shown in full
+			name:     "regex",
+			strategy: NamingRegex,
+			pattern:  `^team-\w-(?P<name>.+)$`,
+			want: map[string]string{
+				"kind-kind":  "kind-kind",
+				"team-a-dev": "team-a-dev",
+				"team-b-dev": "team-b-dev",
+			},
+		},
+	}
+	defer func() { _ = SetNaming("", "") }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetNaming(tt.strategy, tt.pattern); err != nil {
+				t.Fatal(err)
+			}
+			names := displayNames(config)
+			for name, want := range tt.want {
+				if names[name] != want {
+					t.Errorf("display name of %q = %q, want %q", name, names[name], want)
+				}
+			}
+		})
+	}
+
+	if err := SetNaming(NamingRegex, "("); err == nil {
+		t.Error("an invalid pattern was accepted")
+	}
+}
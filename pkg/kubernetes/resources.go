@@ -0,0 +1,366 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// How often resources are sent even if nothing changed so ages stay current
+	resourceInterval = 2 * time.Second
+	maxWarnings      = 20
+)
+
+// NewWatchClientset creates the client used to watch resources
+// in the cluster for the given context.
+//
+// Unlike NewClientset this has no timeout as watches are long lived.
+// This can be replaced to use a fake clientset
+var NewWatchClientset = func(context, filename string) (kubernetes.Interface, error) {
+	config, err := buildConfigFromFlags(context, filename)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(config)
+}
+
+// Pod is a summary of a pod as shown by `kubectl get pods`
+type Pod struct {
+	Name     string
+	Ready    string
+	Status   string
+	Restarts int32
+	Created  time.Time
+}
+
+// Deployment is a summary of a deployment
+type Deployment struct {
+	Name      string
+	Ready     string
+	Available int32
+	Created   time.Time
+}
+
+// Warning is a summary of a warning event
+type Warning struct {
+	Object   string
+	Reason   string
+	Message  string
+	Count    int32
+	LastSeen time.Time
+}
+
+// Resources is a snapshot of the resources in a namespace
+type Resources struct {
+	Pods        []Pod
+	Deployments []Deployment
+	Warnings    []Warning
+	Synced      bool
+	Err         error
+}
+
+// ResourcesMsg is sent when resources being watched change
+//
+// Watcher identifies which watcher sent the message so the receiver
+// can ignore messages from a watcher it has since replaced
+type ResourcesMsg struct {
+	Watcher   *ResourceWatcher
+	Resources Resources
+}
+
+// ResourceWatcher streams pods, deployments and warning events for
+// a single namespace using shared informers
+type ResourceWatcher struct {
+	Context   string
+	Namespace string
+
+	changed   chan struct{}
+	errs      []watchError
+	factory   informers.SharedInformerFactory
+	informers []cache.SharedIndexInformer
+	mu        sync.Mutex
+	stop      chan struct{}
+	synced    bool
+}
+
+// The last watch error of an informer and the resource version it
+// had last synced to when the error happened
+type watchError struct {
+	err     error
+	version string
+}
+
+// Stop client-go logging through klog
+//
+// client-go logs watch failures which would write over the UI.
+// Errors are reported in the resource snapshot instead. This should
+// be called once at startup
+func DiscardClientLogs() {
+	klog.SetOutput(io.Discard)
+	klog.LogToStderr(false)
+}
+
+// Start watching the resources in the namespace of a context
+func WatchResources(context, namespace, filename string) (*ResourceWatcher, error) {
+	client, err := NewWatchClientset(context, filename)
+	if err != nil {
+		return nil, err
+	}
+	w := NewResourceWatcher(client, namespace)
+	w.Context = context
+	return w, nil
+}
+
+// Create a watcher for the namespace using the given client
+//
+// The informers are started immediately and run until Stop is called
+func NewResourceWatcher(client kubernetes.Interface, namespace string) *ResourceWatcher {
+	w := ResourceWatcher{
+		Namespace: namespace,
+		changed:   make(chan struct{}, 1),
+		factory:   informers.NewSharedInformerFactoryWithOptions(client, 0, informers.WithNamespace(namespace)),
+		stop:      make(chan struct{}),
+	}
+
+	w.informers = []cache.SharedIndexInformer{
+		w.factory.Core().V1().Pods().Informer(),
+		w.factory.Apps().V1().Deployments().Informer(),
+		w.factory.Core().V1().Events().Informer(),
+	}
+	w.errs = make([]watchError, len(w.informers))
+	for i, informer := range w.informers {
+		_, _ = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(any) { w.notify(i, nil) },
+			UpdateFunc: func(any, any) { w.notify(i, nil) },
+			DeleteFunc: func(any) { w.notify(i, nil) },
+		})
+		_ = informer.SetWatchErrorHandler(func(_ *cache.Reflector, err error) {
+			w.notify(i, err)
+		})
+	}
+
+	w.factory.Start(w.stop)
+	go func() {
+		w.factory.WaitForCacheSync(w.stop)
+		w.mu.Lock()
+		w.synced = true
+		w.mu.Unlock()
+		w.notify(-1, nil)
+	}()
+	return &w
+}
+
+// Stop all informers for this watcher
+func (w *ResourceWatcher) Stop() {
+	select {
+	case <-w.stop:
+	default:
+		close(w.stop)
+	}
+}
+
+// Wait for the resources to change and send the new snapshot
+//
+// A snapshot is also sent every couple of seconds so ages shown
+// from it stay current. The receiver should call Next again to
+// keep receiving changes
+func (w *ResourceWatcher) Next() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case <-w.stop:
+			return nil
+		case <-w.changed:
+		case <-time.After(resourceInterval):
+		}
+		return ResourcesMsg{Watcher: w, Resources: w.Snapshot()}
+	}
+}
+
+// Get the current resources from the informer caches
+func (w *ResourceWatcher) Snapshot() Resources {
+	w.mu.Lock()
+	r := Resources{Synced: w.synced, Err: w.watchErr()}
+	w.mu.Unlock()
+
+	if pods, err := w.factory.Core().V1().Pods().Lister().Pods(w.Namespace).List(labels.Everything()); err == nil {
+		for _, p := range pods {
+			r.Pods = append(r.Pods, podSummary(p))
+		}
+		slices.SortFunc(r.Pods, func(a, b Pod) int { return strings.Compare(a.Name, b.Name) })
+	}
+
+	if deployments, err := w.factory.Apps().V1().Deployments().Lister().Deployments(w.Namespace).List(labels.Everything()); err == nil {
+		for _, d := range deployments {
+			r.Deployments = append(r.Deployments, deploymentSummary(d))
+		}
+		slices.SortFunc(r.Deployments, func(a, b Deployment) int { return strings.Compare(a.Name, b.Name) })
+	}
+
+	if events, err := w.factory.Core().V1().Events().Lister().Events(w.Namespace).List(labels.Everything()); err == nil {
+		for _, e := range events {
+			if e.Type == corev1.EventTypeWarning {
+				r.Warnings = append(r.Warnings, warningSummary(e))
+			}
+		}
+		slices.SortFunc(r.Warnings, func(a, b Warning) int { return b.LastSeen.Compare(a.LastSeen) })
+		if len(r.Warnings) > maxWarnings {
+			r.Warnings = r.Warnings[:maxWarnings]
+		}
+	}
+	return r
+}
+
+// Record a change to an informer, and its latest watch error if any
+//
+// An event from the informer clears its error. Changes seen by
+// other informers leave it in place
+func (w *ResourceWatcher) notify(informer int, err error) {
+	w.mu.Lock()
+	if informer >= 0 {
+		w.errs[informer] = watchError{err: err}
+		if err != nil {
+			w.errs[informer].version = w.informers[informer].LastSyncResourceVersion()
+		}
+	}
+	w.mu.Unlock()
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Get the first error of any informer still failing
+//
+// An informer that has listed again since its error has synced to a
+// new resource version so its error is dropped. This must be called
+// with the lock held
+func (w *ResourceWatcher) watchErr() error {
+	for i, e := range w.errs {
+		if e.err == nil {
+			continue
+		}
+		if w.informers[i].LastSyncResourceVersion() != e.version {
+			w.errs[i] = watchError{}
+			continue
+		}
+		return e.err
+	}
+	return nil
+}
+
+// Summarise a pod the same way `kubectl get pods` does
+func podSummary(p *corev1.Pod) Pod {
+	pod := Pod{
+		Name:    p.Name,
+		Status:  string(p.Status.Phase),
+		Created: p.CreationTimestamp.Time,
+	}
+	if p.Status.Reason != "" {
+		pod.Status = p.Status.Reason
+	}
+
+	ready := 0
+	for _, c := range p.Status.ContainerStatuses {
+		pod.Restarts += c.RestartCount
+		if c.Ready {
+			ready++
+		}
+		switch {
+		case c.State.Waiting != nil && c.State.Waiting.Reason != "":
+			pod.Status = c.State.Waiting.Reason
+		case c.State.Terminated != nil && c.State.Terminated.Reason != "":
+			pod.Status = c.State.Terminated.Reason
+		}
+	}
+	pod.Ready = fmt.Sprintf("%d/%d", ready, len(p.Spec.Containers))
+
+	if p.DeletionTimestamp != nil {
+		pod.Status = "Terminating"
+	}
+	return pod
+}
+
+func deploymentSummary(d *appsv1.Deployment) Deployment {
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return Deployment{
+		Name:      d.Name,
+		Ready:     fmt.Sprintf("%d/%d", d.Status.ReadyReplicas, replicas),
+		Available: d.Status.AvailableReplicas,
+		Created:   d.CreationTimestamp.Time,
+	}
+}
+
+func warningSummary(e *corev1.Event) Warning {
+	w := Warning{
+		Object:   strings.ToLower(e.InvolvedObject.Kind) + "/" + e.InvolvedObject.Name,
+		Reason:   e.Reason,
+		Message:  strings.TrimSpace(e.Message),
+		Count:    max(1, e.Count),
+		LastSeen: e.LastTimestamp.Time,
+	}
+	if w.LastSeen.IsZero() {
+		w.LastSeen = e.EventTime.Time
+	}
+	if w.LastSeen.IsZero() {
+		w.LastSeen = e.CreationTimestamp.Time
+	}
+	return w
+}
+
+// Get the command to follow the logs of a pod
+func LogsCommand(context, namespace, pod string) string {
+	return kubectlCommand(context, namespace, "logs", "-f", "--all-containers", "--prefix", pod)
+}
+
+// Get the command to open a shell in a pod
+//
+// bash is used if the image has it, otherwise sh
+func ExecCommand(context, namespace, pod string) string {
+	return kubectlCommand(context, namespace, "exec", "-it", pod, "--",
+		"sh", "-c", "command -v bash >/dev/null && exec bash || exec sh")
+}
+
+// Build a kubectl command line with each argument quoted for the shell
+func kubectlCommand(context, namespace string, args ...string) string {
+	args = append([]string{"kubectl", "--context", context, "--namespace", namespace}, args...)
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const testNamespace = "apps"
+
+// Wait for a snapshot from the watcher matching the condition
+func waitForResources(t *testing.T, w *ResourceWatcher, condition func(Resources) bool) Resources {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		msg, ok := w.Next()().(ResourcesMsg)
+		if !ok {
+			t.Fatal("watcher stopped")
+		}
+		if msg.Watcher != w {
+			t.Fatal("message is from another watcher")
+		}
+		if condition(msg.Resources) {
+			return msg.Resources
+		}
+	}
+	t.Fatal("timed out waiting for resources")
+	return Resources{}
+}
+
+// A fake clientset which signals once the pod informer is watching so
+// objects created after that are not missed
+func watchedClientset(objects ...runtime.Object) (*fake.Clientset, chan struct{}) {
+	client := fake.NewSimpleClientset(objects...)
+	started := make(chan struct{})
+	client.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		gvr := action.GetResource()
+		watcher, err := client.Tracker().Watch(gvr, action.GetNamespace())
+		if err != nil {
+			return false, nil, err
+		}
+		close(started)
+		return true, watcher, nil
+	})
+	return client, started
+}
+
+func testPod(name string, modify func(*corev1.Pod)) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if modify != nil {
+		modify(pod)
+	}
+	return pod
+}
+
+func testEvent(name, kind string, seen time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: name},
+		Type:           kind,
+		Reason:         "BackOff",
+		Message:        " restarting \n",
+		LastTimestamp:  metav1.NewTime(seen),
+	}
+}
+
+func TestResourceWatcherSnapshot(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	replicas := int32(3)
+	client, _ := watchedClientset(
+		testPod("web", func(p *corev1.Pod) {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: "sidecar"})
+			p.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{Name: "app", Ready: true, RestartCount: 2},
+				{Name: "sidecar", RestartCount: 3, State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+				}},
+			}
+		}),
+		testPod("api", nil),
+		testPod("other", func(p *corev1.Pod) { p.Namespace = "elsewhere" }),
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespace},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 2, AvailableReplicas: 2},
+		},
+		testEvent("old", corev1.EventTypeWarning, now.Add(-time.Hour)),
+		testEvent("new", corev1.EventTypeWarning, now),
+		testEvent("normal", corev1.EventTypeNormal, now),
+	)
+
+	w := NewResourceWatcher(client, testNamespace)
+	defer w.Stop()
+	r := waitForResources(t, w, func(r Resources) bool { return r.Synced })
+
+	if r.Err != nil {
+		t.Fatalf("unexpected error %v", r.Err)
+	}
+	want := []Pod{
+		{Name: "api", Ready: "0/1", Status: "Running"},
+		{Name: "web", Ready: "1/2", Status: "CrashLoopBackOff", Restarts: 5},
+	}
+	if !slices.Equal(r.Pods, want) {
+		t.Errorf("pods = %+v, want %+v", r.Pods, want)
+	}
+	if len(r.Deployments) != 1 || r.Deployments[0].Ready != "2/3" || r.Deployments[0].Available != 2 {
+		t.Errorf("deployments = %+v, want web 2/3 with 2 available", r.Deployments)
+	}
+
+	warnings := make([]string, 0, len(r.Warnings))
+	for _, warning := range r.Warnings {
+		warnings = append(warnings, warning.Object)
+	}
+	if !slices.Equal(warnings, []string{"pod/new", "pod/old"}) {
+		t.Errorf("warnings = %v, want newest warning first", warnings)
+	}
+	if r.Warnings[0].Message != "restarting" || r.Warnings[0].Count != 1 {
+		t.Errorf("warning = %+v, want trimmed message and a count of 1", r.Warnings[0])
+	}
+}
+
+func TestResourceWatcherLimitsWarnings(t *testing.T) {
+	now := time.Now()
+	objects := make([]runtime.Object, 0, maxWarnings+5)
+	for i := range maxWarnings + 5 {
+		objects = append(objects, testEvent(fmt.Sprintf("pod-%02d", i),
+			corev1.EventTypeWarning, now.Add(time.Duration(i)*time.Minute)))
+	}
+	client, _ := watchedClientset(objects...)
+
+	w := NewResourceWatcher(client, testNamespace)
+	defer w.Stop()
+	r := waitForResources(t, w, func(r Resources) bool { return r.Synced })
+
+	if len(r.Warnings) != maxWarnings {
+		t.Fatalf("got %d warnings, want %d", len(r.Warnings), maxWarnings)
+	}
+	if want := fmt.Sprintf("pod/pod-%02d", maxWarnings+4); r.Warnings[0].Object != want {
+		t.Errorf("first warning = %q, want %q", r.Warnings[0].Object, want)
+	}
+}
+
+func TestResourceWatcherFollowsChanges(t *testing.T) {
+	client, started := watchedClientset(testPod("web", nil))
+	w := NewResourceWatcher(client, testNamespace)
+	defer w.Stop()
+	waitForResources(t, w, func(r Resources) bool { return r.Synced })
+
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("pods are not being watched")
+	}
+
+	pods := client.CoreV1().Pods(testNamespace)
+	if _, err := pods.Create(context.Background(), testPod("api", nil), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitForResources(t, w, func(r Resources) bool { return len(r.Pods) == 2 })
+
+	if err := pods.Delete(context.Background(), "web", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	r := waitForResources(t, w, func(r Resources) bool { return len(r.Pods) == 1 })
+	if r.Pods[0].Name != "api" {
+		t.Errorf("pods = %+v, want only api", r.Pods)
+	}
+}
+
+func TestResourceWatcherReportsWatchErrors(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependWatchReactor("pods", func(k8stesting.Action) (bool, watch.Interface, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	w := NewResourceWatcher(client, testNamespace)
+	defer w.Stop()
+	r := waitForResources(t, w, func(r Resources) bool { return r.Err != nil })
+	if r.Pods != nil {
+		t.Errorf("pods = %+v, want none", r.Pods)
+	}
+}
+
+func TestResourceWatcherKeepsErrorsPerInformer(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	var failing atomic.Bool
+	failing.Store(true)
+	client.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		if failing.Load() {
+			return true, nil, errors.New("connection refused")
+		}
+		watcher, err := client.Tracker().Watch(action.GetResource(), action.GetNamespace())
+		return true, watcher, err
+	})
+
+	w := NewResourceWatcher(client, testNamespace)
+	defer w.Stop()
+	waitForResources(t, w, func(r Resources) bool { return r.Synced && r.Err != nil })
+
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespace}}
+	if _, err := client.AppsV1().Deployments(testNamespace).Create(context.Background(), deployment, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	r := waitForResources(t, w, func(r Resources) bool { return len(r.Deployments) == 1 })
+	if r.Err == nil {
+		t.Error("a deployment change cleared the error of the pod watch")
+	}
+
+	failing.Store(false)
+	if _, err := client.CoreV1().Pods(testNamespace).Create(context.Background(), testPod("api", nil), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitForResources(t, w, func(r Resources) bool { return len(r.Pods) == 1 && r.Err == nil })
+}
+
+func TestResourceWatcherStop(t *testing.T) {
+	w := NewResourceWatcher(fake.NewSimpleClientset(), testNamespace)
+	w.Stop()
+	w.Stop()
+	if msg := w.Next()(); msg != nil {
+		t.Errorf("next after stop = %#v, want nil", msg)
+	}
+}
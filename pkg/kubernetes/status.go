@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"gopkg.in/yaml.v3"
+)
+
+const statusCacheFile = "status.yaml"
+
+// Status is the current context and namespace of a kubeconfig
+type Status struct {
+	Context     string `yaml:"context"`
+	DisplayName string `yaml:"displayName"`
+	Namespace   string `yaml:"namespace"`
+}
+
+type statusEntry struct {
+	Key    string `yaml:"key"`
+	Status Status `yaml:"status"`
+}
+
+// Get the current context and namespace for a kubeconfig
+//
+// This is called on every redraw of the tmux status line so the result
+// is cached on disk and only read from the kubeconfig again once any of
+// its files have changed. `kubeconfig` may be a KUBECONFIG style list.
+//
+// An empty Status is returned when no current context is set.
+func CurrentStatus(kubeconfig string) (Status, error) {
+	filename := statusCachePath()
+	key := ConfigFingerprint(kubeconfig)
+	cache := loadStatusCache(filename)
+	if entry, ok := cache[kubeconfig]; ok && entry.Key == key {
+		return entry.Status, nil
+	}
+
+	config, err := loadMerged(kubeconfig)
+	if err != nil {
+		return Status{}, err
+	}
+	var status Status
+	if ctx, ok := config.Contexts[config.CurrentContext]; ok {
+		status = Status{
+			Context:     config.CurrentContext,
+			DisplayName: displayNames(config)[config.CurrentContext],
+			Namespace:   ctx.Namespace,
+		}
+		if status.Namespace == "" {
+			status.Namespace = "default"
+		}
+	}
+
+	// drop entries for session files that have since been deleted
+	for k := range cache {
+		if _, err := os.Stat(WritableConfig(k)); errors.Is(err, os.ErrNotExist) {
+			delete(cache, k)
+		}
+	}
+	cache[kubeconfig] = statusEntry{Key: key, Status: status}
+	if err := writeStatusCache(filename, cache); err != nil {
+		return status, err
+	}
+	return status, nil
+}
+
+func statusCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, helpers.ExecutableName(), statusCacheFile)
+}
+
+// Load the status cache
+//
+// Any error is treated as an empty cache so the status line always
+// falls back to reading the kubeconfig
+func loadStatusCache(filename string) map[string]statusEntry {
+	cache := make(map[string]statusEntry)
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return cache
+	}
+	if err := yaml.Unmarshal(content, &cache); err != nil {
+		return make(map[string]statusEntry)
+	}
+	return cache
+}
+
+// Write the status cache
+//
+// Unlike the namespace cache this is not locked. Every tmux client
+// redraws its status line at the same time and waiting on a lock would
+// stall them. If two writes race, one entry is lost and is rebuilt on
+// the next redraw.
+func writeStatusCache(filename string, cache map[string]statusEntry) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0750); err != nil {
+		return fmt.Errorf("failed to create cache directory %w", err)
+	}
+	content, err := yaml.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, content, 0600)
+}
@@ -60,13 +60,19 @@ func TeleportClusterList() ([]string, error) {
 	return clusters, nil
 }
 
-func TeleportClusterLogin(cluster string) error {
+// Log in to a teleport kubernetes cluster
+//
+// tsh writes the new context to the first file in KUBECONFIG so this
+// is set explicitly rather than relying on the environment bmx was
+// started from
+func TeleportClusterLogin(cluster, kubeconfig string) error {
 	tsh, err := exec.LookPath("tsh")
 	if err != nil {
 		return errors.ErrUnsupported
 	}
 
-	return bmx.ExecSilent(tsh, []string{
+	_, _, err = bmx.ExecEnv([]string{"KUBECONFIG=" + kubeconfig}, tsh, []string{
 		"kube", "login", cluster,
 	})
+	return err
 }
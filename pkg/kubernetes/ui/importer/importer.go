@@ -0,0 +1,379 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mproffitt/bmx/pkg/components/icons"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/theme"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	defaultWidth = 64
+	visibleRows  = 6
+)
+
+type stage int
+
+const (
+	pathStage stage = iota
+	selectStage
+)
+
+// ImportMsg is sent as the overlay message once the user has
+// chosen which contexts to import
+type ImportMsg struct {
+	Source     *api.Config
+	Selections []kubernetes.ImportSelection
+}
+
+// Model is an overlay for previewing and selecting contexts to
+// import into a kubeconfig file
+type Model struct {
+	candidates []kubernetes.ImportCandidate
+	cursor     int
+	done       bool
+	err        error
+	filename   string
+	help       help.Model
+	input      textinput.Model
+	keymap     *keyMap
+	renaming   bool
+	renames    map[string]string
+	selected   map[string]bool
+	source     *api.Config
+	stage      stage
+	standalone bool
+	styles     styles
+	width      int
+}
+
+type styles struct {
+	conflict lipgloss.Style
+	cursor   lipgloss.Style
+	dim      lipgloss.Style
+	error    lipgloss.Style
+	input    lipgloss.Style
+	name     lipgloss.Style
+	overlay  lipgloss.Style
+	title    lipgloss.Style
+}
+
+// Create a new importer that asks for the file to import
+//
+// `filename` is the kubeconfig contexts will be imported into
+func New(filename string) *Model {
+	m := Model{
+		filename: filename,
+		help:     help.New(),
+		input:    textinput.New(),
+		keymap:   mapKeys(),
+		renames:  make(map[string]string),
+		selected: make(map[string]bool),
+		stage:    pathStage,
+		styles: styles{
+			conflict: lipgloss.NewStyle().Foreground(theme.Colours.Yellow),
+			cursor:   lipgloss.NewStyle().Foreground(theme.Colours.BrightBlue).Bold(true),
+			dim:      lipgloss.NewStyle().Foreground(theme.Colours.BrightBlack),
+			error:    lipgloss.NewStyle().Foreground(theme.Colours.Red),
+			input: lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder(), true).
+				BorderForeground(theme.Colours.Green),
+			name: lipgloss.NewStyle().Foreground(theme.Colours.Blue),
+			overlay: lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder(), true).
+				BorderForeground(theme.Colours.Black).
+				Padding(0, 1),
+			title: lipgloss.NewStyle().Padding(0, 2).
+				Border(lipgloss.RoundedBorder(), false, false, true, false).
+				Foreground(theme.Colours.Yellow),
+		},
+		width: defaultWidth,
+	}
+	m.input.Placeholder = "path to kubeconfig, empty for clipboard"
+	m.input.Width = m.width - 8
+	m.input.Focus()
+	return &m
+}
+
+// Create a new importer for an already loaded config
+//
+// This skips asking for a file and moves straight to selection.
+func NewFromConfig(source *api.Config, filename string) *Model {
+	m := New(filename)
+	m.load(source)
+	return m
+}
+
+// Run the importer as a standalone program
+//
+// Rather than sending an ImportMsg, the program quits once the user
+// has made their selection. Use Selections to read the result.
+func (m *Model) Standalone() *Model {
+	m.standalone = true
+	m.styles.overlay = lipgloss.NewStyle().Padding(0, 1)
+	return m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *Model) Overlay() helpers.UseOverlay {
+	return m
+}
+
+func (m *Model) GetSize() (int, int) {
+	return m.width, lipgloss.Height(m.View())
+}
+
+// The importer has an active dialog while a context is being renamed
+func (m *Model) HasActiveDialog() bool {
+	return m.renaming
+}
+
+// Get the contexts chosen for import
+//
+// This is empty if the import was cancelled
+func (m *Model) Selections() []kubernetes.ImportSelection {
+	if !m.done {
+		return nil
+	}
+	return m.selections()
+}
+
+func (m *Model) selections() []kubernetes.ImportSelection {
+	selections := make([]kubernetes.ImportSelection, 0)
+	for _, name := range kubernetes.ContextNames(m.source) {
+		if m.selected[name] {
+			selections = append(selections, kubernetes.ImportSelection{
+				Name:    name,
+				NewName: m.renames[name],
+			})
+		}
+	}
+	return selections
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	keymsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case m.stage == pathStage:
+		switch {
+		case key.Matches(keymsg, m.keymap.Cancel) && m.standalone:
+			return m, tea.Quit
+		case key.Matches(keymsg, m.keymap.Enter):
+			m.err = m.readSource(strings.TrimSpace(m.input.Value()))
+			return m, nil
+		}
+		m.input, cmd = m.input.Update(msg)
+	case m.renaming:
+		switch {
+		case key.Matches(keymsg, m.keymap.Cancel):
+			m.renaming = false
+		case key.Matches(keymsg, m.keymap.Enter):
+			m.rename(strings.TrimSpace(m.input.Value()))
+		default:
+			m.input, cmd = m.input.Update(msg)
+		}
+	default:
+		switch {
+		case key.Matches(keymsg, m.keymap.Cancel):
+			if m.standalone {
+				return m, tea.Quit
+			}
+		case key.Matches(keymsg, m.keymap.Up):
+			m.cursor = max(0, m.cursor-1)
+		case key.Matches(keymsg, m.keymap.Down):
+			m.cursor = min(len(m.candidates)-1, m.cursor+1)
+		case key.Matches(keymsg, m.keymap.Toggle):
+			name := m.candidates[m.cursor].Name
+			m.selected[name] = !m.selected[name]
+		case key.Matches(keymsg, m.keymap.ToggleAll):
+			all := len(m.selections()) != len(m.candidates)
+			for _, c := range m.candidates {
+				m.selected[c.Name] = all
+			}
+		case key.Matches(keymsg, m.keymap.Rename):
+			m.renaming = true
+			m.input.Reset()
+			m.input.Placeholder = "new context name"
+			m.input.SetValue(m.candidates[m.cursor].NewName)
+			m.input.CursorEnd()
+		case key.Matches(keymsg, m.keymap.Enter):
+			if len(m.selections()) == 0 {
+				m.err = fmt.Errorf("no contexts selected")
+				return m, nil
+			}
+			m.done = true
+			if m.standalone {
+				return m, tea.Quit
+			}
+			return m, helpers.OverlayCmd(ImportMsg{
+				Source:     m.source,
+				Selections: m.selections(),
+			})
+		}
+	}
+	return m, cmd
+}
+
+// Read the config to import from file, or the clipboard if
+// no path is given
+func (m *Model) readSource(path string) error {
+	var (
+		source *api.Config
+		err    error
+	)
+	if path == "" {
+		var content string
+		if content, err = clipboard.ReadAll(); err != nil {
+			return fmt.Errorf("failed to read clipboard %w", err)
+		}
+		source, err = kubernetes.ParseImport([]byte(content))
+	} else {
+		source, err = kubernetes.ReadImportFile(helpers.ExpandHome(path))
+	}
+	if err != nil {
+		return err
+	}
+	m.load(source)
+	return m.err
+}
+
+func (m *Model) load(source *api.Config) {
+	m.source = source
+	m.stage = selectStage
+	m.input.Reset()
+	for _, name := range kubernetes.ContextNames(source) {
+		m.selected[name] = true
+	}
+	m.preview()
+}
+
+func (m *Model) rename(name string) {
+	current := m.candidates[m.cursor].Name
+	switch name {
+	case "":
+		return
+	case current:
+		delete(m.renames, current)
+	default:
+		m.renames[current] = name
+	}
+	m.renaming = false
+	m.preview()
+}
+
+// Rebuild the conflict preview against the destination file
+func (m *Model) preview() {
+	selections := make([]kubernetes.ImportSelection, 0)
+	for _, name := range kubernetes.ContextNames(m.source) {
+		selections = append(selections, kubernetes.ImportSelection{
+			Name:    name,
+			NewName: m.renames[name],
+		})
+	}
+	m.candidates, m.err = kubernetes.PreviewImport(m.source, selections, m.filename)
+	m.cursor = min(m.cursor, max(0, len(m.candidates)-1))
+}
+
+func (m *Model) View() string {
+	width := m.width - 4
+	title := m.styles.title.Render("Import kubeconfig")
+
+	var body string
+	switch {
+	case m.stage == pathStage:
+		body = m.styles.input.Width(width - 2).Render(m.input.View())
+	default:
+		body = m.viewCandidates(width)
+		if m.renaming {
+			body = lipgloss.JoinVertical(lipgloss.Left, body,
+				m.styles.input.Width(width-2).Render(m.input.View()))
+		}
+	}
+
+	parts := []string{title, body}
+	if m.err != nil {
+		parts = append(parts, m.styles.error.Width(width).Render(m.err.Error()))
+	}
+	if m.stage == selectStage {
+		parts = append(parts, m.help.View(m.keymap))
+	}
+	return m.styles.overlay.Width(m.width).Render(
+		lipgloss.JoinVertical(lipgloss.Center, parts...))
+}
+
+func (m *Model) viewCandidates(width int) string {
+	start := max(0, min(m.cursor-visibleRows/2, len(m.candidates)-visibleRows))
+	end := min(len(m.candidates), start+visibleRows)
+
+	rows := make([]string, 0)
+	for i := start; i < end; i++ {
+		c := m.candidates[i]
+
+		check := " "
+		if m.selected[c.Name] {
+			check = string(icons.Tick)
+		}
+		name := c.Name
+		if c.NewName != c.Name {
+			name = fmt.Sprintf("%s %s %s", c.Name, icons.Right, c.NewName)
+		}
+		line := fmt.Sprintf("[%s] %s", check, name)
+		if i == m.cursor {
+			line = m.styles.cursor.Render(ansi.Truncate(line, width, string(icons.Ellipsis)))
+		} else {
+			line = m.styles.name.Render(ansi.Truncate(line, width, string(icons.Ellipsis)))
+		}
+
+		lines := []string{line, m.styles.dim.Render(ansi.Truncate(
+			fmt.Sprintf("    %s (%s)", c.Server, c.Namespace), width, string(icons.Ellipsis)))}
+		for _, d := range c.Describe() {
+			lines = append(lines, m.styles.conflict.Render(ansi.Truncate(
+				"    ! "+d, width, string(icons.Ellipsis))))
+		}
+		rows = append(rows, strings.Join(lines, "\n"))
+	}
+
+	if len(m.candidates) > visibleRows {
+		rows = append(rows, m.styles.dim.Render(
+			fmt.Sprintf("%d/%d", m.cursor+1, len(m.candidates))))
+	}
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(rows, "\n"))
+}
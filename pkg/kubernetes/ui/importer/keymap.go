@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package importer
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/mproffitt/bmx/pkg/components/icons"
+)
+
+type keyMap struct {
+	Cancel    key.Binding
+	Down      key.Binding
+	Enter     key.Binding
+	Rename    key.Binding
+	Toggle    key.Binding
+	ToggleAll key.Binding
+	Up        key.Binding
+}
+
+func (k *keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Toggle, k.Rename, k.Enter}
+}
+
+func (k *keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Toggle, k.ToggleAll},
+		{k.Rename, k.Enter, k.Cancel},
+	}
+}
+
+func mapKeys() *keyMap {
+	return &keyMap{
+		Cancel: key.NewBinding(key.WithKeys("esc"),
+			key.WithHelp("esc", "Cancel")),
+		Down: key.NewBinding(key.WithKeys("down", "j"),
+			key.WithHelp(icons.Down, "move down")),
+		Enter: key.NewBinding(key.WithKeys("enter"),
+			key.WithHelp(icons.Enter, "Import selected contexts")),
+		Rename: key.NewBinding(key.WithKeys("r"),
+			key.WithHelp("r", "Rename context on import")),
+		Toggle: key.NewBinding(key.WithKeys(" "),
+			key.WithHelp(icons.Space, "Select context")),
+		ToggleAll: key.NewBinding(key.WithKeys("a"),
+			key.WithHelp("a", "Select all / none")),
+		Up: key.NewBinding(key.WithKeys("up", "k"),
+			key.WithHelp(icons.Up, "move up")),
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package panel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mproffitt/bmx/pkg/components/toast"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+)
+
+// Maximum number of context names to list in confirmation dialogs
+const maxSummaryNames = 5
+
+// Is the given item marked for a bulk action
+func (m *Model) isMarked(item list.Item) bool {
+	if k, ok := item.(kubernetes.KubeContext); ok {
+		return m.marked[k.Name]
+	}
+	return false
+}
+
+// Toggle the mark on the currently selected context
+func (m *Model) toggleMark() {
+	name := m.selectedName()
+	if name == "" {
+		return
+	}
+	if m.marked[name] {
+		delete(m.marked, name)
+		return
+	}
+	m.marked[name] = true
+}
+
+// Mark all contexts, or clear all marks if any are set
+func (m *Model) toggleMarkAll() {
+	if len(m.marked) > 0 {
+		m.marked = make(map[string]bool)
+		return
+	}
+	for _, item := range m.items {
+		m.marked[item.Name] = true
+	}
+}
+
+// Get the name of the currently selected context
+func (m *Model) selectedName() string {
+	if len(m.lists) == 0 {
+		return ""
+	}
+	if item, ok := m.lists[m.activeList].SelectedItem().(kubernetes.KubeContext); ok {
+		return item.Name
+	}
+	return ""
+}
+
+// Get the contexts an action should be applied to
+//
+// If any contexts are marked, these are returned in display
+// order, otherwise the currently selected context is used
+func (m *Model) targets() []string {
+	targets := make([]string, 0)
+	for _, item := range m.items {
+		if m.marked[item.Name] {
+			targets = append(targets, item.Name)
+		}
+	}
+	if len(targets) == 0 {
+		if name := m.selectedName(); name != "" {
+			targets = append(targets, name)
+		}
+	}
+	return targets
+}
+
+// Report the results of a bulk action
+//
+// If everything succeeded a toast is shown, otherwise a summary of
+// the failures is raised as an error. Marks are cleared either way.
+func (m *Model) report(action string, t toast.ToastType, results kubernetes.Results) tea.Cmd {
+	m.marked = make(map[string]bool)
+	if len(results.Failed()) > 0 {
+		return helpers.NewErrorCmd(errors.New(results.Summary(action)))
+	}
+
+	succeeded := results.Succeeded()
+	message := fmt.Sprintf("%s %d contexts", action, len(succeeded))
+	if len(succeeded) == 1 {
+		message = fmt.Sprintf("%s context %s", action, succeeded[0])
+	}
+	return toast.NewToastCmd(t, message)
+}
+
+// Summarise a list of names for display, truncating after `limit`
+func summariseNames(names []string, limit int) string {
+	if len(names) <= limit {
+		return strings.Join(names, "\n")
+	}
+	return fmt.Sprintf("%s\nand %d more", strings.Join(names[:limit], "\n"), len(names)-limit)
+}
@@ -36,6 +36,7 @@ import (
 type ItemDelegate struct {
 	Styles  list.DefaultItemStyles
 	height  int
+	marked  func(list.Item) bool
 	spacing int
 }
 
@@ -74,6 +75,9 @@ func (d ItemDelegate) Render(w io.Writer, m list.Model, index int, item list.Ite
 	}
 	title = i.Title()
 	desc = i.Description()
+	if d.marked != nil && d.marked(item) {
+		title = string(icons.Tick) + " " + title
+	}
 
 	if m.Width() <= 0 {
 		// short-circuit
@@ -27,11 +27,18 @@ import (
 )
 
 type keyMap struct {
+	Copy      key.Binding
 	Delete    key.Binding
 	Down      key.Binding
 	Enter     key.Binding
+	Forwards  key.Binding
+	Import    key.Binding
+	Layers    key.Binding
 	KillPanel key.Binding
 	Left      key.Binding
+	Lint      key.Binding
+	Mark      key.Binding
+	MarkAll   key.Binding
 	Move      key.Binding
 	Pageup    key.Binding
 	Pagedown  key.Binding
@@ -51,28 +58,45 @@ func (k *keyMap) FullHelp() [][]key.Binding {
 	// with the panel pager. leaving them out for now
 	return [][]key.Binding{
 		{
-			k.Delete, k.Enter, k.KillPanel, k.Move, k.Space, k.Pageup,
+			k.Delete, k.Enter, k.KillPanel, k.Move, k.Copy, k.Space, k.Pageup,
 		},
 		{
 			k.ShiftDel, k.Up, k.Down, k.Left, k.Right, k.Login, k.Pagedown,
 		},
+		{
+			k.Mark, k.MarkAll, k.Import, k.Layers, k.Lint, k.Forwards,
+		},
 	}
 }
 
 func mapKeys() *keyMap {
 	return &keyMap{
+		Copy: key.NewBinding(key.WithKeys("c"),
+			key.WithHelp("c", "Copy context to session")),
 		Delete: key.NewBinding(key.WithKeys("delete", "x"),
 			key.WithHelp("del/x", "Delete the current item")),
 		Down: key.NewBinding(key.WithKeys("down", "j"),
 			key.WithHelp(icons.Down, "move down")),
 		Enter: key.NewBinding(key.WithKeys("enter"),
 			key.WithHelp(icons.Enter, "Set current context")),
+		Forwards: key.NewBinding(key.WithKeys("F"),
+			key.WithHelp("F", "Port-forwards for the session")),
+		Import: key.NewBinding(key.WithKeys("i"),
+			key.WithHelp("i", "Import kubeconfig")),
 		KillPanel: key.NewBinding(key.WithKeys("ctrl+c", "esc"),
 			key.WithHelp("esc", "Close overlays or quit")),
+		Layers: key.NewBinding(key.WithKeys("L"),
+			key.WithHelp("L", "Add or remove shared kubeconfig layers")),
 		Left: key.NewBinding(key.WithKeys("left", "h"),
 			key.WithHelp(icons.Left, "move left")),
+		Lint: key.NewBinding(key.WithKeys("!"),
+			key.WithHelp("!", "Check kubeconfig for problems")),
 		Login: key.NewBinding(key.WithKeys("ctrl+l"),
 			key.WithHelp("ctrl+l", "Login to cluster")),
+		Mark: key.NewBinding(key.WithKeys("v"),
+			key.WithHelp("v", "Mark context for bulk actions")),
+		MarkAll: key.NewBinding(key.WithKeys("V"),
+			key.WithHelp("V", "Mark all / clear marks")),
 		Move: key.NewBinding(key.WithKeys("m"),
 			key.WithHelp("m", "Move context to session")),
 		Pageup: key.NewBinding(key.WithKeys("pgup", "b"),
@@ -96,7 +120,8 @@ func (m *Model) Help() dialog.HelpEntry {
 		Keymap: &km,
 		Title:  "Kubernetes Context Panel",
 		Help: "The kubernetes context panel allows you to interact\nwith" +
-			" contexts inside the kube-config for the current\nsession",
+			" contexts inside the kube-config for the current\nsession.\n\n" +
+			"Mark contexts with `v` to move, copy, delete\nor set the namespace of all of them at once",
 	}
 	return entry
 }
@@ -0,0 +1,112 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package panel
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mproffitt/bmx/pkg/components/dialog"
+	"github.com/mproffitt/bmx/pkg/components/toast"
+	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+// Maximum number of problems listed in the lint dialog
+const maxLintIssues = 8
+
+// Check the session kubeconfig for problems
+//
+// If any are found they are held until the user has chosen
+// whether to fix them from the lint dialog
+func (m *Model) lint() tea.Cmd {
+	issues, err := kubernetes.Lint(m.kubeconfig)
+	if err != nil {
+		return helpers.NewErrorCmd(err)
+	}
+	if len(issues) == 0 {
+		return toast.NewToastCmd(toast.Success, "No problems found in "+m.kubeconfigName())
+	}
+	m.linting = issues
+	return nil
+}
+
+// Fix the problems found by the last lint
+func (m *Model) fix() tea.Cmd {
+	m.linting = nil
+	issues, err := kubernetes.Fix(m.kubeconfig)
+	if err != nil {
+		return helpers.NewErrorCmd(err)
+	}
+	m.reloadContextList()
+
+	var fixed, remaining int
+	for _, issue := range issues {
+		if issue.Fixed {
+			fixed++
+		} else {
+			remaining++
+		}
+	}
+	if remaining > 0 {
+		return toast.NewToastCmd(toast.Warning,
+			fmt.Sprintf("Fixed %d problems, %d need fixing by hand", fixed, remaining))
+	}
+	return toast.NewToastCmd(toast.Success, fmt.Sprintf("Fixed %d problems", fixed))
+}
+
+// Dialog listing the problems found by lint
+//
+// Asks to fix them if any can be fixed safely
+func (m *Model) lintDialog() helpers.UseOverlay {
+	width := 2 * config.DialogWidth
+	fixable := 0
+	lines := make([]string, 0, len(m.linting))
+	for _, issue := range m.linting {
+		prefix := "  "
+		if issue.Fixable {
+			prefix = "* "
+			fixable++
+		}
+		lines = append(lines, prefix+issue.String())
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("Found %d problems in %s\n\n", len(m.linting), m.kubeconfigName()))
+	builder.WriteString(summariseNames(lines, maxLintIssues))
+
+	var d tea.Model
+	if fixable > 0 {
+		builder.WriteString(fmt.Sprintf("\n\nFix the %d problems marked * ?", fixable))
+		d = dialog.NewConfirmDialog(wordwrap.String(builder.String(), width-2), width)
+	} else {
+		builder.WriteString("\n\nNone of these can be fixed automatically")
+		d = dialog.NewOKDialog(wordwrap.String(builder.String(), width-2), width)
+	}
+	return d.(helpers.UseOverlay)
+}
+
+func (m *Model) kubeconfigName() string {
+	return filepath.Base(kubernetes.WritableConfig(m.kubeconfig))
+}
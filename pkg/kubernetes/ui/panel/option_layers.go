@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package panel
+
+import (
+	"slices"
+
+	"github.com/mproffitt/bmx/pkg/components/icons"
+	"github.com/mproffitt/bmx/pkg/components/optionlist"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+)
+
+// Prefix shown against layers the session is already using
+const activeLayer = string(icons.Tick) + " "
+
+func (m *Model) getLayerList() (optionlist.Options, error) {
+	return newLayerList(m.kubeconfig), nil
+}
+
+// Lists the shared kubeconfig files that can be layered beneath the
+// session file. Layers in use are listed first. Any other file can
+// be typed in
+type layers struct {
+	title  string
+	layers []string
+}
+
+func newLayerList(kubeconfig string) *layers {
+	n := layers{
+		title: "Toggle kubeconfig layers",
+	}
+
+	active := kubernetes.ConfigLayers(kubeconfig)
+	for _, layer := range active {
+		n.layers = append(n.layers, activeLayer+layer)
+	}
+
+	writable := kubernetes.WritableConfig(kubeconfig)
+	candidates := append(kubernetes.DefaultLayers(), kubernetes.DefaultConfigFile())
+	for _, layer := range candidates {
+		if layer != writable && !slices.Contains(active, layer) && !slices.Contains(n.layers, layer) {
+			n.layers = append(n.layers, layer)
+		}
+	}
+	return &n
+}
+
+func (n *layers) Title() string {
+	return n.title
+}
+
+func (n *layers) Ordered() bool {
+	return true
+}
+
+func (n *layers) FreeText() bool {
+	return true
+}
+
+func (n *layers) Options() optionlist.Iterator {
+	return func(yield func(key int, val optionlist.Row) bool) {
+		func(yield func(key int, val optionlist.Row) bool) bool {
+			for k, v := range n.layers {
+				if !yield(k, optionlist.Option{Value: v}) {
+					return false
+				}
+			}
+			return true
+		}(yield)
+	}
+}
@@ -20,36 +20,95 @@
 package panel
 
 import (
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mproffitt/bmx/pkg/components/icons"
 	"github.com/mproffitt/bmx/pkg/components/optionlist"
 	"github.com/mproffitt/bmx/pkg/kubernetes"
 )
 
 func (m *Model) getNamespaceList() (optionlist.Options, error) {
-	return newNamespaceList(m.context, m.kubeconfig)
+	return m.newNamespaceList(kubernetes.CachedNamespaces(m.context, m.kubeconfig)), nil
+}
+
+// Refresh the namespaces of the current and selected contexts in
+// the background once their cache is stale, so the chooser opens
+// with an up to date list
+//
+// Each context is checked at most once per NamespaceMaxAge so an
+// unreachable cluster is not retried on every watch
+func (m *Model) refreshNamespaces() tea.Cmd {
+	names := make([]string, 0, 2)
+	for _, item := range m.items {
+		if item.IsCurrentContext {
+			names = append(names, item.Name)
+		}
+	}
+	if name := m.selectedName(); name != "" && !slices.Contains(names, name) {
+		names = append(names, name)
+	}
+
+	cmds := make([]tea.Cmd, 0, len(names))
+	for _, name := range names {
+		if checked, ok := m.namespaces[name]; ok && time.Since(checked) < kubernetes.NamespaceMaxAge {
+			continue
+		}
+		entry := kubernetes.CachedNamespaces(name, m.kubeconfig)
+		if !entry.Stale() {
+			m.namespaces[name] = entry.Updated
+			continue
+		}
+		m.namespaces[name] = time.Now()
+		cmds = append(cmds, kubernetes.RefreshNamespacesCmd(name, m.kubeconfig))
+	}
+	return tea.Batch(cmds...)
 }
 
+// Namespaces are shown from the cache while a refresh runs in the
+// background. Any namespace can be typed in, which is the only option
+// on clusters where listing namespaces is forbidden
 type namespaces struct {
 	title      string
-	context    string
-	filename   string
 	namespaces []string
 }
 
-func newNamespaceList(context, filename string) (*namespaces, error) {
+func (m *Model) newNamespaceList(entry kubernetes.NamespaceEntry) *namespaces {
 	n := namespaces{
-		title:    "Namespaces",
-		context:  kubernetes.GetFullName(context, filename),
-		filename: filename,
+		title:      "Namespaces",
+		namespaces: entry.All(),
 	}
-	var err error
-	n.namespaces, err = kubernetes.GetNamespaces(n.context, n.filename)
-	return &n, err
+
+	switch {
+	case entry.Forbidden:
+		n.title = "Namespace (type a name)"
+	case entry.Updated.IsZero():
+		n.title = "Namespaces (loading" + string(icons.Ellipsis) + ")"
+	}
+
+	// always offer the namespace the context is currently using
+	for _, item := range m.items {
+		if item.Name == m.context && !slices.Contains(n.namespaces, item.Namespace) {
+			n.namespaces = append(n.namespaces, item.Namespace)
+		}
+	}
+	return &n
 }
 
 func (n *namespaces) Title() string {
 	return n.title
 }
 
+// Recently used namespaces are listed first
+func (n *namespaces) Ordered() bool {
+	return true
+}
+
+func (n *namespaces) FreeText() bool {
+	return true
+}
+
 func (n *namespaces) Options() optionlist.Iterator {
 	return func(yield func(key int, val optionlist.Row) bool) {
 		func(yield func(key int, val optionlist.Row) bool) bool {
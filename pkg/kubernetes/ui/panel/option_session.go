@@ -27,16 +27,20 @@ import (
 )
 
 func (m *Model) getSessionList() (optionlist.Options, error) {
-	return newSessionList(), nil
+	title := "Move to session"
+	if m.copying {
+		title = "Copy to session"
+	}
+	return newSessionList(title), nil
 }
 
 type sessions struct {
 	title string
 }
 
-func newSessionList() *sessions {
+func newSessionList(title string) *sessions {
 	n := sessions{
-		title: "Sessions",
+		title: title,
 	}
 	return &n
 }
@@ -20,7 +20,6 @@
 package panel
 
 import (
-	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mproffitt/bmx/pkg/components/optionlist"
 	"github.com/mproffitt/bmx/pkg/helpers"
@@ -33,11 +32,12 @@ const (
 	ClusterLogin
 	Namespace
 	Session
+	Layers
 )
 
 func (m *Model) optionChooser(o OptionType, update *string) tea.Cmd {
 	if update != nil {
-		*update = m.lists[m.activeList].SelectedItem().(list.DefaultItem).Title()
+		*update = m.selectedName()
 	}
 	m.optionType = o
 
@@ -52,6 +52,8 @@ func (m *Model) optionChooser(o OptionType, update *string) tea.Cmd {
 			options, err = m.getClusterList()
 		case Session:
 			options, err = m.getSessionList()
+		case Layers:
+			options, err = m.getLayerList()
 		}
 	}
 	if err != nil {
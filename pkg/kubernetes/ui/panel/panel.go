@@ -21,7 +21,9 @@ package panel
 
 import (
 	"math"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/paginator"
@@ -33,6 +35,7 @@ import (
 	"github.com/mproffitt/bmx/pkg/config"
 	"github.com/mproffitt/bmx/pkg/helpers"
 	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/providers"
 	"github.com/mproffitt/bmx/pkg/theme"
 	"github.com/muesli/reflow/truncate"
 )
@@ -45,29 +48,35 @@ const (
 )
 
 type Model struct {
-	activeItem int
-	activeList int
-	cols       int
-	config     *config.Config
-	context    string
-	focused    bool
-	force      bool
-	height     int
-	items      []kubernetes.KubeContext
-	keymap     *keyMap
-	kubeconfig string
-	lists      []list.Model
-	listWidth  int
-	options    tea.Model
-	optionType OptionType
-	paginator  *paginator.Model
-	rows       int
-	session    string
-	styles     contextStyles
-	todelete   string
-	tomove     string
-	viewport   viewport.Model
-	width      int
+	activeItem  int
+	activeList  int
+	cols        int
+	config      *config.Config
+	context     string
+	copying     bool
+	fingerprint string
+	focused     bool
+	force       bool
+	height      int
+	items       []kubernetes.KubeContext
+	keymap      *keyMap
+	kubeconfig  string
+	linting     []kubernetes.Issue
+	lists       []list.Model
+	listWidth   int
+	marked      map[string]bool
+	namespaces  map[string]time.Time
+	options     tea.Model
+	optionType  OptionType
+	paginator   *paginator.Model
+	rows        int
+	selection   []string
+	session     string
+	standalone  bool
+	styles      contextStyles
+	todelete    []string
+	viewport    viewport.Model
+	width       int
 }
 
 type contextStyles struct {
@@ -91,6 +100,8 @@ func NewKubectxPane(c *config.Config, session string, rows, cols, columnWidth in
 		config:     c,
 		keymap:     mapKeys(),
 		listWidth:  min(columnWidth, KubernetesListWidth),
+		marked:     make(map[string]bool),
+		namespaces: make(map[string]time.Time),
 		paginator: &paginator.Model{
 			ActiveDot:    lipgloss.NewStyle().Foreground(theme.Colours.BrightWhite).Render("•"),
 			ArabicFormat: "%d/%d",
@@ -147,7 +158,11 @@ func (m *Model) Overlay() helpers.UseOverlay {
 		return m.options.(helpers.UseOverlay).Overlay()
 	}
 
-	if m.todelete != "" {
+	if len(m.linting) > 0 {
+		return m.lintDialog()
+	}
+
+	if len(m.todelete) > 0 {
 		if m.force {
 			m.force = false
 			return nil
@@ -159,7 +174,7 @@ func (m *Model) Overlay() helpers.UseOverlay {
 				Bold(true).
 				Foreground(theme.Colours.BrightBlue).
 				Padding(1).
-				Render(m.todelete)))
+				Render(summariseNames(m.todelete, maxSummaryNames))))
 		builder.WriteString("\ndeleting means you will no longer be logged in to this cluster")
 		dialog := dialog.NewConfirmDialog(builder.String(), config.DialogWidth)
 		return dialog.(helpers.UseOverlay)
@@ -169,19 +184,52 @@ func (m *Model) Overlay() helpers.UseOverlay {
 }
 
 func (m *Model) RequiresOverlay() bool {
-	return m.options != nil || (m.todelete != "" && !m.force)
+	return m.options != nil || len(m.linting) > 0 || (len(m.todelete) > 0 && !m.force)
 }
 
 func (m *Model) GetSize() (int, int) {
 	return m.width, m.height
 }
 
+// Run the panel outside of the session manager
+//
+// Contexts are always listed, regardless of whether session
+// contexts are managed, and changes to layers only apply to
+// the running panel as there is no session to store them against
+func (m *Model) Standalone() *Model {
+	m.standalone = true
+	return m
+}
+
+// Show the contexts of a session from the kubeconfig
+// held in its environment
+func (m *Model) SetSession(session string) tea.Model {
+	if session == m.session {
+		return m
+	}
+	return m.UpdateContextList(session, providers.Kubeconfig(session))
+}
+
+// Get the fingerprint of the kubeconfig when the contexts were loaded
+func (m *Model) Fingerprint() string {
+	return m.fingerprint
+}
+
 func (m *Model) UpdateContextList(session, kubeconfig string) tea.Model {
 	if session == m.session {
 		return m
 	}
 	m.session = session
+	return m.SetKubeconfig(kubeconfig)
+}
+
+// Show the contexts from the given kubeconfig
+//
+// `kubeconfig` may be a single file or a KUBECONFIG style list
+func (m *Model) SetKubeconfig(kubeconfig string) tea.Model {
 	m.kubeconfig = kubeconfig
+	m.marked = make(map[string]bool)
+	m.namespaces = make(map[string]time.Time)
 	m.reloadContextList()
 
 	m.activeItem = 0
@@ -197,6 +245,27 @@ func (m *Model) UpdateContextList(session, kubeconfig string) tea.Model {
 	return m
 }
 
+// Select the named context, moving to the page that holds it
+//
+// If the context is not in the current list the selection is unchanged
+func (m *Model) SelectContext(name string) tea.Model {
+	for i := range m.lists {
+		for j, item := range m.lists[i].Items() {
+			if item.(kubernetes.KubeContext).Name != name {
+				continue
+			}
+			m.activeList = i
+			m.activeItem = j
+			m.lists[i].Select(j)
+			if m.paginator.PerPage > 0 {
+				m.paginator.Page = i / m.paginator.PerPage
+			}
+			return m
+		}
+	}
+	return m
+}
+
 func (m *Model) setActiveContextPage() {
 	page := m.paginator.Page
 	for i := range m.paginator.TotalPages {
@@ -279,6 +348,7 @@ func (m *Model) createActiveDelegate() ItemDelegate {
 
 func (m *Model) createBaseDelegate() ItemDelegate {
 	delegate := NewItemDelegate()
+	delegate.marked = m.isMarked
 	delegate.Styles.NormalTitle = delegate.Styles.NormalTitle.
 		Foreground(theme.Colours.Blue)
 	delegate.Styles.NormalDesc = delegate.Styles.NormalTitle.
@@ -345,6 +415,7 @@ func (m *Model) createPaginatedColumns() []string {
 
 func (m *Model) createShadedDelegate() ItemDelegate {
 	delegate := NewItemDelegate()
+	delegate.marked = m.isMarked
 	delegate.Styles.NormalTitle = delegate.Styles.NormalTitle.
 		Foreground(theme.Colours.Black)
 
@@ -358,13 +429,21 @@ func (m *Model) createShadedDelegate() ItemDelegate {
 }
 
 func (m *Model) reloadContextList() {
+	m.fingerprint = kubernetes.ConfigFingerprint(m.kubeconfig)
 	contexts, err := kubernetes.KubeContextList(
-		m.config.ManageSessionKubeContext, m.kubeconfig)
+		m.standalone || m.config.ManageSessionKubeContext, m.kubeconfig)
 	if err != nil {
 		contexts = make([]kubernetes.KubeContext, 0)
 	}
 
 	m.items = contexts
+	for name := range m.marked {
+		if !slices.ContainsFunc(m.items, func(k kubernetes.KubeContext) bool {
+			return k.Name == name
+		}) {
+			delete(m.marked, name)
+		}
+	}
 	m.lists = m.createKubeLists()
 	if len(m.lists) > 0 {
 		if m.activeList >= len(m.lists) {
@@ -377,6 +456,17 @@ func (m *Model) reloadContextList() {
 	}
 }
 
+// Reload the contexts after the kubeconfig has changed on disk
+//
+// The selected context stays selected if it still exists
+func (m *Model) Reload() {
+	name := m.selectedName()
+	m.reloadContextList()
+	pages := float64(len(m.items)) / float64(m.rows*m.cols)
+	m.paginator.TotalPages = max(1, int(math.Ceil(pages)))
+	m.SelectContext(name)
+}
+
 func (m *Model) switchContext() error {
 	context := m.lists[m.activeList].SelectedItem().(kubernetes.KubeContext)
 	contextName := context.Name
@@ -21,15 +21,21 @@ package panel
 
 import (
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
-	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/log"
 	"github.com/mproffitt/bmx/pkg/components/dialog"
+	"github.com/mproffitt/bmx/pkg/components/optionlist"
 	"github.com/mproffitt/bmx/pkg/components/toast"
 	"github.com/mproffitt/bmx/pkg/helpers"
 	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/kubernetes/ui/importer"
+	"github.com/mproffitt/bmx/pkg/portforward/ui/forwards"
 	"github.com/mproffitt/bmx/pkg/tmux"
 )
 
@@ -42,11 +48,17 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, m.keymap.KillPanel):
+			// let the overlay close its own dialog first
+			if d, ok := m.options.(interface{ HasActiveDialog() bool }); ok && d.HasActiveDialog() {
+				break
+			}
 			if m.options != nil {
 				m.options = nil
 			}
 			m.context = ""
-			m.tomove = ""
+			m.copying = false
+			m.linting = nil
+			m.selection = nil
 		case key.Matches(msg, m.keymap.Left):
 			m.activeItem = m.lists[m.activeList].Cursor()
 			m.activeList = (m.activeList - 1)
@@ -104,15 +116,50 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// END
 
 		case key.Matches(msg, m.keymap.Delete, m.keymap.ShiftDel):
-			m.todelete = m.lists[m.activeList].SelectedItem().(list.DefaultItem).Title()
+			m.todelete = m.targets()
 			if key.Matches(msg, m.keymap.ShiftDel) {
 				m.force = true
 				return m, kubernetes.ContextDeleteCmd()
 			}
 		case key.Matches(msg, m.keymap.Space):
-			m.optionChooser(Namespace, &m.context)
-		case key.Matches(msg, m.keymap.Move):
-			m.optionChooser(Session, &m.tomove)
+			m.selection = m.targets()
+			cmd = m.optionChooser(Namespace, &m.context)
+			m.namespaces[m.context] = time.Now()
+			cmds = append(cmds, cmd, kubernetes.RefreshNamespacesCmd(m.context, m.kubeconfig))
+		case key.Matches(msg, m.keymap.Move, m.keymap.Copy):
+			// contexts can only be moved or copied between tmux sessions
+			copying := key.Matches(msg, m.keymap.Copy)
+			if !tmux.IsRunning() {
+				action := "Moving"
+				if copying {
+					action = "Copying"
+				}
+				cmds = append(cmds, toast.NewToastCmd(toast.Warning, action+" contexts requires tmux"))
+				break
+			}
+			m.selection = m.targets()
+			m.copying = copying
+			cmd = m.optionChooser(Session, nil)
+			cmds = append(cmds, cmd)
+		case key.Matches(msg, m.keymap.Mark):
+			m.toggleMark()
+		case key.Matches(msg, m.keymap.MarkAll):
+			m.toggleMarkAll()
+		case key.Matches(msg, m.keymap.Import):
+			m.options = importer.New(m.kubeconfig)
+			cmds = append(cmds, m.options.Init())
+		case key.Matches(msg, m.keymap.Forwards):
+			// forwards are supervised against a tmux session
+			if m.standalone || !tmux.IsRunning() {
+				cmds = append(cmds, toast.NewToastCmd(toast.Warning, "Port-forwards require a tmux session"))
+				break
+			}
+			m.options = m.forwards()
+		case key.Matches(msg, m.keymap.Layers):
+			cmd = m.optionChooser(Layers, nil)
+			cmds = append(cmds, cmd)
+		case key.Matches(msg, m.keymap.Lint):
+			cmds = append(cmds, m.lint())
 		case key.Matches(msg, m.keymap.Login):
 			m.optionChooser(ClusterLogin, nil)
 		case key.Matches(msg, m.keymap.Enter):
@@ -129,13 +176,39 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, helpers.NewErrorCmd(err)
 		}
 		m.lists[m.activeList].Select((m.activeItem))
-	case kubernetes.ContextDeleteMsg:
-		if m.todelete != "" {
-			if err := kubernetes.DeleteContext(m.todelete, m.kubeconfig); err != nil {
-				return m, helpers.NewErrorCmd(err)
+	case kubernetes.WatchMsg:
+		// Only reload if the files changed since they were last read.
+		// Changes made by the panel itself already reload the list
+		if msg.Kubeconfig == m.kubeconfig && msg.Fingerprint != m.fingerprint {
+			m.Reload()
+		}
+		if f, ok := m.options.(*forwards.Model); ok {
+			f.Reload()
+		}
+		cmds = append(cmds, kubernetes.WatchCmd(m.kubeconfig), m.refreshNamespaces())
+	case kubernetes.NamespacesMsg:
+		if msg.Err != nil {
+			log.Debug("namespace refresh failed", "context", msg.Context, "error", msg.Err)
+		}
+		if m.optionType == Namespace && m.context == msg.Context && m.kubeconfig == msg.Kubeconfig {
+			// background refreshes fail quietly, only say so when
+			// the namespaces are being chosen
+			if msg.Err != nil {
+				cmds = append(cmds, toast.NewToastCmd(toast.Warning, "Unable to refresh namespaces"))
 			}
-			cmds = append(cmds, toast.NewToastCmd(toast.Warning, "Deleted context "+m.todelete))
-			m.todelete = ""
+			if options, ok := m.options.(*optionlist.OptionModel); ok {
+				list := m.newNamespaceList(msg.Entry)
+				if msg.Err != nil {
+					list.title = "Namespaces (unavailable)"
+				}
+				options.SetOptions(list)
+			}
+		}
+	case kubernetes.ContextDeleteMsg:
+		if len(m.todelete) > 0 {
+			results := kubernetes.DeleteContexts(m.todelete, m.kubeconfig)
+			cmds = append(cmds, m.report("Deleted", toast.Warning, results))
+			m.todelete = nil
 			m.reloadContextList()
 		}
 	case helpers.OverlayMsg:
@@ -158,43 +231,109 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 					cmds = append(cmds, helpers.ReloadManagerCmd())
 				}
-				err = kubernetes.MoveContext(m.tomove, m.kubeconfig, newconfig)
-				if err != nil {
-					return m, helpers.NewErrorCmd(err)
+				var results kubernetes.Results
+				if m.copying {
+					results = kubernetes.CopyContexts(m.selection, m.kubeconfig, newconfig)
+					cmds = append(cmds, m.report("Copied", toast.Info, results))
+				} else {
+					results = kubernetes.MoveContexts(m.selection, m.kubeconfig, newconfig)
+					cmds = append(cmds, m.report("Moved", toast.Info, results))
 				}
-				cmds = append(cmds, toast.NewToastCmd(toast.Info, "Moved context "+m.tomove))
+				m.copying = false
+				m.selection = nil
 				m.reloadContextList()
 
 			case Namespace:
 				log.Debug("namespace", "value", value)
-				if err := kubernetes.SetNamespace(m.context, value, m.kubeconfig); err != nil {
-					return m, helpers.NewErrorCmd(err)
+				results := kubernetes.SetNamespaces(m.selection, value, m.kubeconfig)
+				if err := kubernetes.RecordNamespace(m.context, m.kubeconfig, value); err != nil {
+					log.Debug("failed to record namespace", "context", m.context, "error", err)
 				}
+				cmds = append(cmds, m.report("Set namespace "+value+" on", toast.Info, results))
 				m.context = ""
+				m.selection = nil
 				m.reloadContextList()
 
+			case Layers:
+				cmd = m.toggleLayer(helpers.ExpandHome(strings.TrimPrefix(value, activeLayer)))
+				cmds = append(cmds, cmd)
+
 			case ClusterLogin:
 				log.Debug("clusterlogin", "value", value)
-				if err := kubernetes.TeleportClusterLogin(value); err != nil {
+				if err := kubernetes.TeleportClusterLogin(value, m.kubeconfig); err != nil {
 					return m, helpers.NewErrorCmd(err)
 				}
 				m.reloadContextList()
 				m.setActiveContextPage()
 			}
 			m.optionType = None
+		case importer.ImportMsg:
+			m.options = nil
+			results := kubernetes.ImportContexts(value.Source, value.Selections, false, m.kubeconfig)
+			cmds = append(cmds, m.report("Imported", toast.Success, results))
+			m.reloadContextList()
 		case dialog.Status:
 			switch value {
 			case dialog.Confirm:
-				if m.todelete != "" {
+				if len(m.linting) > 0 {
+					cmds = append(cmds, m.fix())
+				} else if len(m.todelete) > 0 {
 					cmd = kubernetes.ContextDeleteCmd()
 					cmds = append(cmds, cmd)
 				}
 			case dialog.Cancel:
-				if m.todelete != "" {
-					m.todelete = ""
-				}
+				m.linting = nil
+				m.todelete = nil
 			}
 		}
 	}
 	return m, tea.Batch(cmds...)
 }
+
+// Add or remove a shared kubeconfig layer for the session
+//
+// The session file always stays first so it remains the file
+// all changes are written to
+func (m *Model) toggleLayer(layer string) tea.Cmd {
+	writable := kubernetes.WritableConfig(m.kubeconfig)
+	if layer == "" || layer == writable {
+		return nil
+	}
+
+	action := "Added layer "
+	layers := kubernetes.ConfigLayers(m.kubeconfig)
+	if slices.Contains(layers, layer) {
+		action = "Removed layer "
+		layers = slices.DeleteFunc(layers, func(l string) bool {
+			return l == layer
+		})
+	} else {
+		layers = append(layers, layer)
+	}
+
+	kubeconfig := kubernetes.JoinConfigFiles(append([]string{writable}, layers...)...)
+	if !m.standalone {
+		if err := tmux.SetSessionEnvironment(m.session, "KUBECONFIG", kubeconfig); err != nil {
+			return helpers.NewErrorCmd(err)
+		}
+	}
+	m.kubeconfig = kubeconfig
+	m.reloadContextList()
+	return toast.NewToastCmd(toast.Info, action+filepath.Base(layer))
+}
+
+// Open the port-forwards for the session
+//
+// New forwards use the selected context and its namespace
+func (m *Model) forwards() *forwards.Model {
+	var context, namespace string
+	if len(m.lists) > 0 {
+		if item, ok := m.lists[m.activeList].SelectedItem().(kubernetes.KubeContext); ok {
+			context, namespace = item.Name, item.Namespace
+		}
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	return forwards.New(m.session, context, namespace)
+}
@@ -0,0 +1,383 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mproffitt/bmx/pkg/components/dialog"
+	"github.com/mproffitt/bmx/pkg/components/icons"
+	"github.com/mproffitt/bmx/pkg/components/overlay"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/theme"
+	"github.com/mproffitt/bmx/pkg/tmux"
+)
+
+// Height is the number of lines the pane takes up including its border
+const Height = 12
+
+// Model is a quick view of the pods, deployments and warning
+// events in the current context and namespace of a session
+type Model struct {
+	cursor      int
+	display     string
+	err         error
+	fingerprint string
+	focused     bool
+	height      int
+	keymap      *keyMap
+	kubeconfig  string
+	path        string
+	resources   kubernetes.Resources
+	session     string
+	styles      styles
+	watcher     *kubernetes.ResourceWatcher
+	width       int
+}
+
+type styles struct {
+	cursor  lipgloss.Style
+	dim     lipgloss.Style
+	error   lipgloss.Style
+	header  lipgloss.Style
+	normal  lipgloss.Style
+	focused lipgloss.Style
+	status  map[string]lipgloss.Style
+	title   lipgloss.Style
+}
+
+type keyMap struct {
+	Down key.Binding
+	Exec key.Binding
+	Logs key.Binding
+	Up   key.Binding
+}
+
+func (k *keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Logs, k.Exec}
+}
+
+func (k *keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{k.Up, k.Down, k.Logs, k.Exec}}
+}
+
+// Create a new resource view
+//
+// Nothing is watched until a session is set with SetTarget
+func New() *Model {
+	m := Model{
+		keymap: &keyMap{
+			Down: key.NewBinding(key.WithKeys("down", "j"),
+				key.WithHelp(icons.Down, "move down")),
+			Exec: key.NewBinding(key.WithKeys("e"),
+				key.WithHelp("e", "Open a shell in the pod in a new window")),
+			Logs: key.NewBinding(key.WithKeys("l"),
+				key.WithHelp("l", "Follow pod logs in a new window")),
+			Up: key.NewBinding(key.WithKeys("up", "k"),
+				key.WithHelp(icons.Up, "move up")),
+		},
+		styles: styles{
+			cursor: lipgloss.NewStyle().Foreground(theme.Colours.BrightBlue).Bold(true),
+			dim:    lipgloss.NewStyle().Foreground(theme.Colours.BrightBlack),
+			error:  lipgloss.NewStyle().Foreground(theme.Colours.Red),
+			header: lipgloss.NewStyle().Foreground(theme.Colours.Blue).Bold(true),
+			normal: lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder(), true).
+				BorderForeground(theme.Colours.Black).
+				PaddingLeft(1).PaddingRight(1),
+			focused: lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder(), true).
+				BorderForeground(theme.Colours.Blue).
+				PaddingLeft(1).PaddingRight(1),
+			status: map[string]lipgloss.Style{
+				"Running":   lipgloss.NewStyle().Foreground(theme.Colours.Green),
+				"Succeeded": lipgloss.NewStyle().Foreground(theme.Colours.BrightBlack),
+				"Completed": lipgloss.NewStyle().Foreground(theme.Colours.BrightBlack),
+				"Pending":   lipgloss.NewStyle().Foreground(theme.Colours.Yellow),
+			},
+			title: lipgloss.NewStyle().Foreground(theme.Colours.Yellow),
+		},
+	}
+	return &m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) Blur() tea.Model {
+	m.focused = false
+	return m
+}
+
+func (m *Model) Focus() tea.Model {
+	m.focused = true
+	return m
+}
+
+func (m *Model) GetSize() (int, int) {
+	return m.width, m.height
+}
+
+func (m *Model) SetSize(width, height int) tea.Model {
+	m.width = width
+	m.height = height
+	return m
+}
+
+func (m *Model) Help() dialog.HelpEntry {
+	km := help.KeyMap(m.keymap)
+	return dialog.HelpEntry{
+		Keymap: &km,
+		Title:  "Kubernetes resources",
+		Help: "Shows the pods, deployments and recent warnings in the\n" +
+			"current context and namespace of the session",
+	}
+}
+
+// Get the session currently being shown
+func (m *Model) Session() string {
+	return m.session
+}
+
+// Watch the current context and namespace of a session
+//
+// The watch is only restarted if the session, its context or its
+// namespace have changed since it was last set
+func (m *Model) SetTarget(session, path, kubeconfig string) tea.Cmd {
+	m.fingerprint = kubernetes.ConfigFingerprint(kubeconfig)
+	status, err := kubernetes.CurrentStatus(kubeconfig)
+	if err == nil && status.Context == "" {
+		err = fmt.Errorf("session %q has no current context", session)
+	}
+
+	m.path = path
+	if m.watcher != nil && session == m.session && kubeconfig == m.kubeconfig &&
+		m.watcher.Context == status.Context && m.watcher.Namespace == status.Namespace {
+		return nil
+	}
+
+	m.Stop()
+	m.session, m.kubeconfig = session, kubeconfig
+	m.display = status.DisplayName
+	m.cursor = 0
+	m.resources = kubernetes.Resources{}
+	m.err = err
+	if err != nil {
+		return nil
+	}
+
+	m.watcher, m.err = kubernetes.WatchResources(status.Context, status.Namespace, kubeconfig)
+	if m.err != nil {
+		return nil
+	}
+	return m.watcher.Next()
+}
+
+// Has the kubeconfig being watched been written since the target
+// was set
+//
+// The context or namespace of the session may have changed so the
+// target should be set again
+func (m *Model) Changed(msg kubernetes.WatchMsg) bool {
+	return m.kubeconfig != "" && msg.Kubeconfig == m.kubeconfig &&
+		msg.Fingerprint != m.fingerprint
+}
+
+// Stop watching resources
+func (m *Model) Stop() {
+	if m.watcher != nil {
+		m.watcher.Stop()
+		m.watcher = nil
+	}
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case kubernetes.ResourcesMsg:
+		// drop anything still in flight from a replaced watcher
+		if msg.Watcher != m.watcher {
+			return m, nil
+		}
+		m.resources = msg.Resources
+		m.cursor = min(m.cursor, max(0, len(m.resources.Pods)-1))
+		return m, m.watcher.Next()
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keymap.Up):
+			m.cursor = max(0, m.cursor-1)
+		case key.Matches(msg, m.keymap.Down):
+			m.cursor = min(max(0, len(m.resources.Pods)-1), m.cursor+1)
+		case key.Matches(msg, m.keymap.Logs):
+			return m, m.open("logs", kubernetes.LogsCommand)
+		case key.Matches(msg, m.keymap.Exec):
+			return m, m.open("exec", kubernetes.ExecCommand)
+		}
+	}
+	return m, nil
+}
+
+// Open a new window in the session running a command against the
+// selected pod, then switch to it
+func (m *Model) open(prefix string, command func(context, namespace, pod string) string) tea.Cmd {
+	if m.watcher == nil || len(m.resources.Pods) == 0 {
+		return nil
+	}
+	pod := m.resources.Pods[m.cursor].Name
+	cmd := command(m.watcher.Context, m.watcher.Namespace, pod)
+	if _, err := tmux.NewWindow(m.session, prefix+" "+pod, m.path, cmd); err != nil {
+		return helpers.NewErrorCmd(err)
+	}
+	if err := tmux.AttachSession(m.session); err != nil {
+		return helpers.NewErrorCmd(err)
+	}
+	return tea.Quit
+}
+
+func (m *Model) View() string {
+	style := m.styles.normal
+	if m.focused {
+		style = m.styles.focused
+	}
+	width := max(0, m.width-style.GetHorizontalFrameSize())
+	height := max(0, m.height-style.GetVerticalFrameSize())
+
+	var body string
+	switch {
+	case m.err != nil:
+		body = m.styles.error.Width(width).Render(m.err.Error())
+	case m.watcher == nil:
+		body = m.styles.dim.Render("No context selected")
+	case !m.resources.Synced:
+		body = m.styles.dim.Render("Loading resources" + string(icons.Ellipsis))
+	default:
+		podsWidth := width * 55 / 100
+		right := width - podsWidth - 2
+		deployments := m.viewDeployments(right, height/2)
+		body = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(podsWidth).MarginRight(2).Render(m.viewPods(podsWidth, height)),
+			lipgloss.JoinVertical(lipgloss.Left, deployments,
+				m.viewWarnings(right, height-lipgloss.Height(deployments))))
+		if m.resources.Err != nil {
+			body = lipgloss.JoinVertical(lipgloss.Left,
+				m.styles.error.Render(ansi.Truncate(m.resources.Err.Error(), width, string(icons.Ellipsis))), body)
+		}
+	}
+	body = lipgloss.NewStyle().Width(width).Height(height).MaxHeight(height).Render(body)
+	doc := style.Render(body)
+
+	title := "Resources"
+	if m.watcher != nil {
+		title = fmt.Sprintf("Resources : %s (%s)", m.display, m.watcher.Namespace)
+	}
+	title = m.styles.title.Render(ansi.Truncate(title, max(0, m.width-4), string(icons.Ellipsis)))
+	return overlay.PlaceOverlay(2, 0, title, doc, false)
+}
+
+func (m *Model) viewPods(width, height int) string {
+	if len(m.resources.Pods) == 0 {
+		return m.styles.dim.Render("No pods")
+	}
+
+	name := max(10, width-38)
+	rows := []string{m.styles.header.Render(fmt.Sprintf("%-*s %5s %-16s %8s %5s",
+		name, "POD", "READY", "STATUS", "RESTARTS", "AGE"))}
+
+	visible := max(1, height-1)
+	start := max(0, min(m.cursor-visible/2, len(m.resources.Pods)-visible))
+	end := min(len(m.resources.Pods), start+visible)
+	for i := start; i < end; i++ {
+		p := m.resources.Pods[i]
+		status := lipgloss.NewStyle().Foreground(theme.Colours.Red)
+		if s, ok := m.styles.status[p.Status]; ok {
+			status = s
+		}
+		line := fmt.Sprintf("%-*s %5s ", name, ansi.Truncate(p.Name, name, string(icons.Ellipsis)), p.Ready) +
+			status.Render(fmt.Sprintf("%-16s", ansi.Truncate(p.Status, 16, string(icons.Ellipsis)))) +
+			fmt.Sprintf(" %8d %5s", p.Restarts, age(p.Created))
+		if i == m.cursor && m.focused {
+			line = m.styles.cursor.Render(ansi.Strip(line))
+		}
+		rows = append(rows, line)
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (m *Model) viewDeployments(width, height int) string {
+	if len(m.resources.Deployments) == 0 {
+		return m.styles.dim.Render("No deployments")
+	}
+
+	name := max(10, width-12)
+	rows := []string{m.styles.header.Render(fmt.Sprintf("%-*s %5s %5s", name, "DEPLOYMENT", "READY", "AGE"))}
+	for i, d := range m.resources.Deployments {
+		if i >= max(1, height-1) {
+			break
+		}
+		rows = append(rows, fmt.Sprintf("%-*s %5s %5s", name,
+			ansi.Truncate(d.Name, name, string(icons.Ellipsis)), d.Ready, age(d.Created)))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (m *Model) viewWarnings(width, height int) string {
+	if height < 2 {
+		return ""
+	}
+	if len(m.resources.Warnings) == 0 {
+		return m.styles.dim.Render("No warnings")
+	}
+
+	rows := []string{m.styles.header.Render("WARNINGS")}
+	for i, w := range m.resources.Warnings {
+		if i >= height-1 {
+			break
+		}
+		line := fmt.Sprintf("%5s %s %s: %s", age(w.LastSeen), w.Reason, w.Object, w.Message)
+		rows = append(rows, m.styles.error.Render(ansi.Truncate(line, width, string(icons.Ellipsis))))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// Format an age the same way kubectl does
+func age(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
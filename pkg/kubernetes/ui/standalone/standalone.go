@@ -0,0 +1,227 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package standalone
+
+import (
+	"math"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mproffitt/bmx/pkg/components/dialog"
+	"github.com/mproffitt/bmx/pkg/components/overlay"
+	"github.com/mproffitt/bmx/pkg/components/toast"
+	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/kubernetes/ui/panel"
+)
+
+// The panel is the only focusable element so overlays
+// always return focus to it
+const panelFocus overlay.FocusType = 0
+
+type HasActiveDialog interface {
+	HasActiveDialog() bool
+}
+
+// Model runs the kubernetes context panel on its own for
+// any kubeconfig file, without needing a tmux session
+type Model struct {
+	config     *config.Config
+	dialog     tea.Model
+	height     int
+	keymap     *keyMap
+	kubeconfig string
+	overlay    *overlay.Container
+	panel      tea.Model
+	toast      *toast.Model
+	width      int
+}
+
+type keyMap struct {
+	Help key.Binding
+	Quit key.Binding
+}
+
+// Create a new standalone context manager
+//
+// `kubeconfig` may be a single file or a KUBECONFIG style list
+func New(c *config.Config, kubeconfig string) *Model {
+	return &Model{
+		config: c,
+		keymap: &keyMap{
+			Help: key.NewBinding(key.WithKeys("?", "f1")),
+			Quit: key.NewBinding(key.WithKeys("ctrl+c", "esc")),
+		},
+		kubeconfig: kubeconfig,
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	return kubernetes.WatchCmd(m.kubeconfig)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd  tea.Cmd
+		cmds []tea.Cmd
+		err  error
+	)
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.resize()
+	case tea.KeyMsg:
+		if m.panel == nil {
+			break
+		}
+		if m.dialog != nil {
+			m.dialog, cmd = m.dialog.Update(msg)
+			return m, cmd
+		}
+		switch {
+		case key.Matches(msg, m.keymap.Quit):
+			if m.overlay == nil {
+				return m, tea.Quit
+			}
+			// let the panel clear its state before the overlay closes
+			m.panel, cmd = m.panel.Update(msg)
+			if d, ok := m.overlay.Model.(HasActiveDialog); ok && d.HasActiveDialog() {
+				return m, cmd
+			}
+			m.overlay = nil
+			return m, cmd
+		case key.Matches(msg, m.keymap.Help) && m.overlay == nil:
+			m.dialog = dialog.HelpDialog(m.panel.(dialog.UseHelp).Help())
+			return m, nil
+		}
+
+		if m.overlay != nil {
+			var model tea.Model
+			model, cmd = m.overlay.Model.Update(msg)
+			m.overlay.Model = model.(helpers.UseOverlay)
+			return m, cmd
+		}
+
+		m.panel, cmd = m.panel.Update(msg)
+		cmds = append(cmds, cmd)
+		if m.panel.(*panel.Model).RequiresOverlay() {
+			m.overlay = overlay.New(&m.panel, panelFocus)
+			if m.overlay.Model == nil {
+				m.overlay = nil
+			}
+		}
+	case kubernetes.ContextDeleteMsg, kubernetes.ContextChangeMsg, kubernetes.NamespacesMsg:
+		if m.panel != nil {
+			m.panel, cmd = m.panel.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	case kubernetes.WatchMsg:
+		if m.panel == nil {
+			cmds = append(cmds, kubernetes.WatchCmd(msg.Kubeconfig))
+			break
+		}
+		m.panel, cmd = m.panel.Update(msg)
+		cmds = append(cmds, cmd)
+	case helpers.OverlayMsg:
+		m.overlay = nil
+		if m.panel != nil {
+			m.panel, cmd = m.panel.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	case dialog.DialogStatusMsg:
+		if !msg.Done {
+			break
+		}
+		// The help and error dialogs belong to this model, any
+		// other dialog is an overlay owned by the panel
+		if m.dialog != nil {
+			m.dialog = nil
+			break
+		}
+		if m.overlay != nil {
+			cmds = append(cmds, helpers.OverlayCmd(msg.Selected))
+		}
+	case helpers.ErrorMsg:
+		m.overlay = nil
+		err = msg.Error
+	case toast.NewToastMsg:
+		m.toast = toast.New(msg.Type, msg.Message)
+		cmds = append(cmds, m.toast.Init())
+	case toast.FrameMsg:
+		if m.toast != nil {
+			m.toast, cmd = m.toast.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	if err != nil {
+		m.dialog = dialog.NewOKDialog(err.Error(), config.DialogWidth)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// Size the panel to fill the window
+//
+// The panel is created on the first resize as the number of rows
+// and columns it shows depends on the size of the window
+func (m *Model) resize() {
+	width := m.width - 4
+	height := m.height - (panel.PanelTitle + panel.PanelFooter)
+
+	cols := max(1, int(math.Floor(float64(width-2)/float64(panel.KubernetesListWidth))))
+	colWidth := int(math.Floor(float64(width-2) / float64(cols)))
+	rows := max(1, int(math.Floor(float64(height)/float64(panel.KubernetesRowHeight))))
+
+	if m.panel == nil {
+		p := panel.NewKubectxPane(m.config, "", rows, cols, colWidth).Standalone()
+		p.SetSize(width, height, colWidth)
+		p.SetKubeconfig(m.kubeconfig)
+		m.panel = p.Focus()
+		return
+	}
+	m.panel = m.panel.(*panel.Model).SetSize(width, height, colWidth)
+}
+
+func (m *Model) View() string {
+	if m.panel == nil {
+		return ""
+	}
+	doc := lipgloss.NewStyle().Padding(1, 1, 0, 1).Render(m.panel.View())
+
+	if m.toast != nil {
+		doc = overlay.PlaceOverlay(1, ((m.height - m.toast.Height) - 3), m.toast.View(), doc, false)
+	}
+	if m.dialog != nil {
+		dw, _ := m.dialog.(helpers.UseOverlay).GetSize()
+		w := m.width/2 - max(dw, config.DialogWidth)/2
+		return overlay.PlaceOverlay(w, 10, m.dialog.View(), doc, false)
+	}
+	if m.overlay != nil {
+		w, h := m.overlay.Model.GetSize()
+		w = m.width/2 - max(w, config.DialogWidth)/2
+		h = m.height/2 - max(h, 10)/2
+		return overlay.PlaceOverlay(w, h, m.overlay.View(), doc, false)
+	}
+	return doc
+}
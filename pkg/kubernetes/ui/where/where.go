@@ -0,0 +1,199 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package where
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mproffitt/bmx/pkg/components/icons"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/theme"
+)
+
+const (
+	defaultWidth = 64
+	visibleRows  = 8
+)
+
+// JumpMsg is sent when a context has been chosen from the search
+//
+// The receiver should switch to the session and select the context
+type JumpMsg struct {
+	Session string
+	Context string
+}
+
+func JumpCmd(session, context string) tea.Cmd {
+	return func() tea.Msg {
+		return JumpMsg{
+			Session: session,
+			Context: context,
+		}
+	}
+}
+
+// Model is an overlay for searching the contexts held by every session
+type Model struct {
+	cursor  int
+	err     error
+	index   []kubernetes.IndexEntry
+	input   textinput.Model
+	matches []kubernetes.IndexEntry
+	styles  styles
+	width   int
+}
+
+type styles struct {
+	cursor  lipgloss.Style
+	dim     lipgloss.Style
+	error   lipgloss.Style
+	input   lipgloss.Style
+	name    lipgloss.Style
+	overlay lipgloss.Style
+	session lipgloss.Style
+	title   lipgloss.Style
+}
+
+// Create a new context search
+//
+// The index is built from every session kubeconfig when the
+// search is opened
+func New() *Model {
+	m := Model{
+		input: textinput.New(),
+		styles: styles{
+			cursor: lipgloss.NewStyle().Foreground(theme.Colours.BrightBlue).Bold(true),
+			dim:    lipgloss.NewStyle().Foreground(theme.Colours.BrightBlack),
+			error:  lipgloss.NewStyle().Foreground(theme.Colours.Red),
+			input: lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder(), true).
+				BorderForeground(theme.Colours.Green),
+			name: lipgloss.NewStyle().Foreground(theme.Colours.Blue),
+			overlay: lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder(), true).
+				BorderForeground(theme.Colours.Black).
+				Padding(0, 1),
+			session: lipgloss.NewStyle().Foreground(theme.Colours.Green),
+			title: lipgloss.NewStyle().Padding(0, 2).
+				Border(lipgloss.RoundedBorder(), false, false, true, false).
+				Foreground(theme.Colours.Yellow),
+		},
+		width: defaultWidth,
+	}
+	m.input.Placeholder = "context, cluster or server"
+	m.input.Width = m.width - 8
+	m.input.Focus()
+	m.index, m.err = kubernetes.ContextIndex()
+	m.filter()
+	return &m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) GetSize() (int, int) {
+	return m.width, 0
+}
+
+func (m *Model) Overlay() helpers.UseOverlay {
+	return m
+}
+
+func (m *Model) filter() {
+	m.matches = kubernetes.FilterIndex(m.index, m.input.Value())
+	m.cursor = min(m.cursor, max(0, len(m.matches)-1))
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keymsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keymsg.String() {
+	case "up":
+		m.cursor = max(0, m.cursor-1)
+	case "down":
+		m.cursor = min(max(0, len(m.matches)-1), m.cursor+1)
+	case "enter":
+		if len(m.matches) == 0 {
+			return m, nil
+		}
+		entry := m.matches[m.cursor]
+		return m, JumpCmd(entry.Session, entry.Context)
+	default:
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		m.filter()
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *Model) View() string {
+	width := m.width - 4
+	title := m.styles.title.Render("Find context")
+
+	parts := []string{title, m.viewMatches(width)}
+	if m.err != nil {
+		parts = append(parts, m.styles.error.Width(width).Render(m.err.Error()))
+	}
+	parts = append(parts, m.styles.input.Width(width-2).Render(m.input.View()))
+	return m.styles.overlay.Width(m.width).Render(
+		lipgloss.JoinVertical(lipgloss.Center, parts...))
+}
+
+func (m *Model) viewMatches(width int) string {
+	if len(m.matches) == 0 {
+		return m.styles.dim.Width(width).Render("No matching contexts")
+	}
+
+	start := max(0, min(m.cursor-visibleRows/2, len(m.matches)-visibleRows))
+	end := min(len(m.matches), start+visibleRows)
+
+	rows := make([]string, 0)
+	for i := start; i < end; i++ {
+		e := m.matches[i]
+
+		style := m.styles.name
+		if i == m.cursor {
+			style = m.styles.cursor
+		}
+		line := style.Render(ansi.Truncate(e.DisplayName, width/2, string(icons.Ellipsis))) +
+			m.styles.dim.Render(" in ") +
+			m.styles.session.Render(ansi.Truncate(e.Session, width/2-4, string(icons.Ellipsis)))
+
+		rows = append(rows, strings.Join([]string{line, m.styles.dim.Render(ansi.Truncate(
+			fmt.Sprintf("    %s (%s)", e.Server, e.Namespace), width, string(icons.Ellipsis)))}, "\n"))
+	}
+
+	if len(m.matches) > visibleRows {
+		rows = append(rows, m.styles.dim.Render(
+			fmt.Sprintf("%d/%d", m.cursor+1, len(m.matches))))
+	}
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(rows, "\n"))
+}
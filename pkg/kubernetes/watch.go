@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// How often kubeconfig files are checked for changes
+const watchInterval = 2 * time.Second
+
+// WatchMsg is sent each time the kubeconfig files are checked
+//
+// Fingerprint identifies the state of the files when they were checked
+// and changes whenever any of them are written, created or removed
+type WatchMsg struct {
+	Kubeconfig  string
+	Fingerprint string
+}
+
+// Check the kubeconfig for changes after the watch interval
+//
+// Files are polled rather than watched with inotify as kubectl and tsh
+// replace the file on write, and the files may not exist yet. The
+// receiver should call WatchCmd again to keep watching
+func WatchCmd(kubeconfig string) tea.Cmd {
+	return tea.Tick(watchInterval, func(time.Time) tea.Msg {
+		return WatchMsg{
+			Kubeconfig:  kubeconfig,
+			Fingerprint: ConfigFingerprint(kubeconfig),
+		}
+	})
+}
+
+// Build a fingerprint of a kubeconfig from the modification
+// time and size of each of its files
+//
+// `kubeconfig` may be a KUBECONFIG style list
+func ConfigFingerprint(kubeconfig string) string {
+	parts := make([]string, 0)
+	for _, file := range ConfigFiles(kubeconfig) {
+		info, err := os.Stat(file)
+		if err != nil {
+			parts = append(parts, file+":missing")
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", file, info.ModTime().UnixNano(), info.Size()))
+	}
+	return strings.Join(parts, ",")
+}
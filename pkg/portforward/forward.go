@@ -0,0 +1,198 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package portforward
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/tmux"
+)
+
+// EnvVar is the tmux session variable holding the forwards
+// declared for that session
+//
+// Like KUBECONFIG, the session environment is the live copy.
+// It is written to the bmx config when sessions are saved and
+// set again when they are restored by `bmx load`
+const EnvVar = "BMX_FORWARDS"
+
+// ErrNoSession is returned when forwards are managed without a tmux session
+var ErrNoSession = errors.New("port-forwards require a tmux session")
+
+// Parse a forward from a target and a port mapping
+//
+// Ports are given the same way as to `kubectl port-forward`, either
+// `local:remote` or a single port used for both. The remote port may
+// be a named port on the target
+func Parse(context, namespace, target, ports string) (helpers.Forward, error) {
+	f := helpers.Forward{
+		Context:   context,
+		Namespace: namespace,
+		Target:    target,
+	}
+
+	local, remote, found := strings.Cut(ports, ":")
+	if !found {
+		remote = local
+	}
+	port, err := strconv.Atoi(local)
+	if err != nil {
+		return f, fmt.Errorf("invalid local port %q", local)
+	}
+	f.LocalPort = port
+	f.RemotePort = remote
+	return f, Validate(f)
+}
+
+// Validate checks a forward has everything needed to start it
+func Validate(f helpers.Forward) error {
+	switch {
+	case f.Context == "":
+		return errors.New("a forward requires a context")
+	case f.Target == "":
+		return errors.New("a forward requires a target")
+	case !strings.Contains(f.Target, "/"):
+		return fmt.Errorf("target %q must be in the form type/name", f.Target)
+	case f.LocalPort < 1 || f.LocalPort > 65535:
+		return fmt.Errorf("local port %d is out of range", f.LocalPort)
+	case f.RemotePort == "":
+		return errors.New("a forward requires a remote port")
+	}
+	return nil
+}
+
+// Describe a forward as it would be given to kubectl
+func Describe(f helpers.Forward) string {
+	return fmt.Sprintf("%s %d:%s", f.Target, f.LocalPort, f.RemotePort)
+}
+
+// Load the forwards declared for a session
+func Load(session string) ([]helpers.Forward, error) {
+	forwards := make([]helpers.Forward, 0)
+	value := tmux.GetTmuxEnvVar(session, EnvVar)
+	if value == "" {
+		return forwards, nil
+	}
+	if err := json.Unmarshal([]byte(value), &forwards); err != nil {
+		return forwards, fmt.Errorf("failed to read forwards for session %q %w", session, err)
+	}
+	return forwards, nil
+}
+
+// Store the forwards declared for a session
+//
+// This replaces every forward for the session. The supervisor picks
+// up the change the next time it checks the session
+func Store(session string, forwards []helpers.Forward) error {
+	if session == "" {
+		return ErrNoSession
+	}
+	if forwards == nil {
+		forwards = make([]helpers.Forward, 0)
+	}
+	content, err := json.Marshal(forwards)
+	if err != nil {
+		return err
+	}
+	return tmux.SetSessionEnvironment(session, EnvVar, string(content))
+}
+
+// Add a forward to a session and make sure it is running
+//
+// Local ports are unique, so a forward on the same local port as
+// an existing one replaces it
+func Add(session string, f helpers.Forward) error {
+	if err := Validate(f); err != nil {
+		return err
+	}
+	return modify(session, func(forwards []helpers.Forward) ([]helpers.Forward, error) {
+		i := slices.IndexFunc(forwards, func(e helpers.Forward) bool {
+			return e.LocalPort == f.LocalPort
+		})
+		if i < 0 {
+			return append(forwards, f), nil
+		}
+		forwards[i] = f
+		return forwards, nil
+	})
+}
+
+// Remove the forward on the given local port
+func Remove(session string, port int) error {
+	return modify(session, func(forwards []helpers.Forward) ([]helpers.Forward, error) {
+		i, err := find(forwards, port)
+		if err != nil {
+			return nil, err
+		}
+		return slices.Delete(forwards, i, i+1), nil
+	})
+}
+
+// Start or stop the forward on the given local port
+//
+// Stopped forwards are kept so they can be started again later
+func SetStopped(session string, port int, stopped bool) error {
+	return modify(session, func(forwards []helpers.Forward) ([]helpers.Forward, error) {
+		i, err := find(forwards, port)
+		if err != nil {
+			return nil, err
+		}
+		forwards[i].Stopped = stopped
+		return forwards, nil
+	})
+}
+
+func find(forwards []helpers.Forward, port int) (int, error) {
+	i := slices.IndexFunc(forwards, func(f helpers.Forward) bool {
+		return f.LocalPort == port
+	})
+	if i < 0 {
+		return i, fmt.Errorf("no forward on local port %d", port)
+	}
+	return i, nil
+}
+
+// Apply a change to the forwards for a session, then make sure the
+// supervisor is running to act on it
+func modify(session string, change func([]helpers.Forward) ([]helpers.Forward, error)) error {
+	if session == "" {
+		return ErrNoSession
+	}
+	forwards, err := Load(session)
+	if err != nil {
+		return err
+	}
+	if forwards, err = change(forwards); err != nil {
+		return err
+	}
+	if err := Store(session, forwards); err != nil {
+		return err
+	}
+	if len(forwards) == 0 {
+		return nil
+	}
+	return Start(session)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package portforward
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mproffitt/bmx/pkg/helpers"
+)
+
+// How long to wait for kubectl output to close after it exits
+const waitDelay = 5 * time.Second
+
+// How much of the end of kubectl stderr is kept for the error
+const stderrTail = 4096
+
+// Forwarder runs a single port-forward
+//
+// Forward blocks until the forward drops or ctx is cancelled and
+// must call ready once the local port is accepting connections.
+// Returning, for any reason other than ctx being cancelled, is
+// treated as the forward having dropped and it will be restarted.
+//
+// This can be replaced to use a fake forwarder
+type Forwarder interface {
+	Forward(ctx context.Context, kubeconfig string, f helpers.Forward, ready func()) error
+}
+
+// DefaultForwarder is used by the supervisor to start forwards
+var DefaultForwarder Forwarder = KubectlForwarder{}
+
+// KubectlForwarder runs forwards with `kubectl port-forward`
+//
+// kubectl resolves services and deployments to a running pod,
+// which client-go leaves to the caller
+type KubectlForwarder struct{}
+
+func (KubectlForwarder) Forward(ctx context.Context, kubeconfig string, f helpers.Forward, ready func()) error {
+	kubectl, err := exec.LookPath("kubectl")
+	if err != nil {
+		return fmt.Errorf("kubectl is required for port-forwards %w", err)
+	}
+
+	args := []string{"port-forward", "--context", f.Context}
+	if f.Namespace != "" {
+		args = append(args, "--namespace", f.Namespace)
+	}
+	args = append(args, f.Target, strconv.Itoa(f.LocalPort)+":"+f.RemotePort)
+
+	cmd := exec.CommandContext(ctx, kubectl, args...)
+	cmd.Env = append(os.Environ(), "KUBECONFIG="+kubeconfig)
+	// don't wait forever on output held open by anything kubectl started
+	cmd.WaitDelay = waitDelay
+	var stderr tailWriter
+	cmd.Stderr = &stderr
+	cmd.Stdout = &readyWriter{ready: ready}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	if msg := lastLine(stderr.String()); msg != "" {
+		return errors.New(msg)
+	}
+	if err == nil {
+		err = errors.New("kubectl exited")
+	}
+	return err
+}
+
+// readyWriter watches kubectl output for the forward becoming ready
+//
+// kubectl prints one `Forwarding from` line per address it listens
+// on, then a line for every connection it handles
+type readyWriter struct {
+	buffer  []byte
+	isReady bool
+	ready   func()
+}
+
+func (w *readyWriter) Write(p []byte) (int, error) {
+	if w.isReady {
+		return len(p), nil
+	}
+	w.buffer = append(w.buffer, p...)
+	for {
+		line, rest, found := bytes.Cut(w.buffer, []byte("\n"))
+		if !found {
+			break
+		}
+		w.buffer = rest
+		if bytes.HasPrefix(line, []byte("Forwarding from")) {
+			w.isReady = true
+			w.buffer = nil
+			w.ready()
+			break
+		}
+	}
+	return len(p), nil
+}
+
+// tailWriter keeps only the end of what is written to it
+//
+// A forward can run for days and kubectl writes a line to stderr
+// for every failed connection, only the last of which is reported
+type tailWriter struct {
+	buffer []byte
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+	if over := len(w.buffer) - stderrTail; over > 0 {
+		w.buffer = append(w.buffer[:0], w.buffer[over:]...)
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	return string(w.buffer)
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
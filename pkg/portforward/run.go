@@ -0,0 +1,283 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package portforward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/tmux"
+	"gopkg.in/yaml.v3"
+)
+
+// How often the supervisor checks the session for changes
+const pollInterval = 2 * time.Second
+
+// How long to wait for a supervisor to exit once asked to stop
+const stopTimeout = waitDelay + 5*time.Second
+
+// ErrRunning is returned when a supervisor is already running
+// for the session
+var ErrRunning = errors.New("port-forwards are already supervised for this session")
+
+// Run the supervisor for a session
+//
+// This keeps the forwards declared in the session environment running
+// until ctx is cancelled, the session is killed, or it no longer has
+// any forwards. Status is written to the cache so the panel and
+// `bmx forward list` can show it.
+//
+// Only one supervisor may run for each session
+func Run(ctx context.Context, session string, forwarder Forwarder) error {
+	unlock, err := lockSupervisor(session)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := os.WriteFile(pidPath(session), []byte(strconv.Itoa(os.Getpid())), 0600); err != nil {
+		return err
+	}
+	defer os.Remove(pidPath(session))
+
+	filename := statusPath(session)
+	supervisor := NewSupervisor(forwarder)
+	defer func() {
+		supervisor.Stop()
+		_ = os.Remove(filename)
+	}()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if !tmux.HasSession(session) {
+			log.Info("session has gone, stopping forwards", "session", session)
+			return nil
+		}
+
+		forwards, err := Load(session)
+		if err != nil {
+			log.Error("failed to load forwards", "session", session, "error", err)
+		} else {
+			if len(forwards) == 0 {
+				log.Info("no forwards declared, stopping", "session", session)
+				return nil
+			}
+			supervisor.Sync(tmux.GetTmuxEnvVar(session, "KUBECONFIG"), forwards)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-supervisor.Changed():
+		}
+
+		if err := writeStatus(filename, supervisor.Status()); err != nil {
+			log.Error("failed to write status", "session", session, "error", err)
+		}
+	}
+}
+
+// Start the supervisor for a session if it isn't already running
+//
+// The supervisor is started as `bmx forward run <session>` in its
+// own process group so it outlives the popup that started it.
+// Its output is logged alongside the status file
+func Start(session string) error {
+	if session == "" {
+		return ErrNoSession
+	}
+	if IsRunning(session) {
+		return nil
+	}
+
+	cmd, err := SupervisorCommand(session)
+	if err != nil {
+		return err
+	}
+	logfile := statusPath(session) + ".log"
+	if err := os.MkdirAll(filepath.Dir(logfile), 0750); err != nil {
+		return fmt.Errorf("failed to create cache directory %w", err)
+	}
+	out, err := os.OpenFile(logfile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start port-forward supervisor %w", err)
+	}
+	return cmd.Process.Release()
+}
+
+// Stop the supervisor for a session and wait for it to exit
+//
+// This is a no-op if no supervisor is running
+func Stop(session string) error {
+	if !IsRunning(session) {
+		return nil
+	}
+	content, err := os.ReadFile(pidPath(session))
+	if err != nil {
+		return fmt.Errorf("failed to find port-forward supervisor %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return fmt.Errorf("failed to find port-forward supervisor %w", err)
+	}
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("failed to stop port-forward supervisor %w", err)
+	}
+
+	deadline := time.Now().Add(stopTimeout)
+	for IsRunning(session) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("port-forward supervisor for %q did not stop", session)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return nil
+}
+
+// SupervisorCommand builds the command that runs the supervisor
+// for a session
+//
+// This can be replaced if bmx is not the running executable
+var SupervisorCommand = func(session string) (*exec.Cmd, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(executable, "forward", "run", session), nil
+}
+
+// If a supervisor is running for the session
+func IsRunning(session string) bool {
+	unlock, err := lockSupervisor(session)
+	if err != nil {
+		return errors.Is(err, ErrRunning)
+	}
+	unlock()
+	return false
+}
+
+// Get the status of every forward declared for a session
+//
+// If the supervisor isn't running, every forward is reported as stopped
+func Statuses(session string) ([]Status, error) {
+	forwards, err := Load(session)
+	if err != nil {
+		return nil, err
+	}
+
+	running := IsRunning(session)
+	reported := make(map[int]Status)
+	if running {
+		content, err := os.ReadFile(statusPath(session))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		statuses := make([]Status, 0)
+		if err := yaml.Unmarshal(content, &statuses); err != nil {
+			return nil, fmt.Errorf("failed to read forward status %w", err)
+		}
+		for _, s := range statuses {
+			reported[s.Forward.LocalPort] = s
+		}
+	}
+
+	statuses := make([]Status, 0, len(forwards))
+	for _, f := range forwards {
+		s, ok := reported[f.LocalPort]
+		if !ok || s.Forward != f {
+			// not yet picked up by the supervisor
+			s = Status{Forward: f, State: Stopped}
+			if !f.Stopped && running {
+				s.State = Starting
+			}
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+func statusPath(session string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, helpers.ExecutableName(), "forwards", session+".yaml")
+}
+
+func pidPath(session string) string {
+	return statusPath(session) + ".pid"
+}
+
+func writeStatus(filename string, statuses []Status) error {
+	content, err := yaml.Marshal(statuses)
+	if err != nil {
+		return err
+	}
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// Take the supervisor lock for a session
+//
+// An flock is used rather than a pid file so the lock is released
+// by the kernel if the supervisor dies
+func lockSupervisor(session string) (func(), error) {
+	filename := statusPath(session) + ".lock"
+	if err := os.MkdirAll(filepath.Dir(filename), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %w", err)
+	}
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrRunning
+		}
+		return nil, err
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		_ = f.Close()
+	}, nil
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package portforward
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mproffitt/bmx/pkg/helpers"
+)
+
+const (
+	minBackoff = 1 * time.Second
+	maxBackoff = 30 * time.Second
+
+	// A forward must stay up this long before its backoff is
+	// reset, so one that drops as soon as it connects still backs off
+	stableAfter = 10 * time.Second
+)
+
+// State of a forward
+type State string
+
+const (
+	Stopped    State = "stopped"
+	Starting   State = "starting"
+	Running    State = "running"
+	Restarting State = "restarting"
+)
+
+// Status of a forward as reported by the supervisor
+//
+// Error holds the reason the forward last dropped
+type Status struct {
+	Forward  helpers.Forward `yaml:"forward"`
+	State    State           `yaml:"state"`
+	Restarts int             `yaml:"restarts"`
+	Error    string          `yaml:"error,omitempty"`
+	Since    time.Time       `yaml:"since"`
+}
+
+// Supervisor keeps a set of forwards running
+//
+// Each forward runs in its own goroutine and is restarted with an
+// increasing backoff each time it drops. The backoff is reset once
+// the forward has been ready for long enough
+type Supervisor struct {
+	changed   chan struct{}
+	declared  []helpers.Forward
+	forwarder Forwarder
+	mu        sync.Mutex
+	workers   map[int]*worker
+
+	// the clock used for backoff, which can be replaced in tests
+	after func(time.Duration) <-chan time.Time
+	now   func() time.Time
+}
+
+type worker struct {
+	cancel     context.CancelFunc
+	done       chan struct{}
+	forward    helpers.Forward
+	kubeconfig string
+	status     Status
+}
+
+// Create a new supervisor using the given forwarder
+func NewSupervisor(forwarder Forwarder) *Supervisor {
+	return &Supervisor{
+		changed:   make(chan struct{}, 1),
+		forwarder: forwarder,
+		workers:   make(map[int]*worker),
+		after:     time.After,
+		now:       time.Now,
+	}
+}
+
+// Changed is signalled whenever the state of a forward changes
+func (s *Supervisor) Changed() <-chan struct{} {
+	return s.changed
+}
+
+// Sync the running forwards with those declared
+//
+// Forwards that were removed, stopped or changed are stopped, and
+// any that are not yet running are started. A change of kubeconfig
+// restarts every forward
+func (s *Supervisor) Sync(kubeconfig string, forwards []helpers.Forward) {
+	s.mu.Lock()
+	s.declared = forwards
+	desired := make(map[int]helpers.Forward)
+	for _, f := range forwards {
+		if !f.Stopped {
+			desired[f.LocalPort] = f
+		}
+	}
+
+	stopping := make([]*worker, 0)
+	for port, w := range s.workers {
+		if f, ok := desired[port]; !ok || f != w.forward || kubeconfig != w.kubeconfig {
+			w.cancel()
+			stopping = append(stopping, w)
+			delete(s.workers, port)
+		}
+	}
+	s.mu.Unlock()
+
+	// The old forward must release its port before a
+	// replacement can listen on it
+	for _, w := range stopping {
+		<-w.done
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for port, f := range desired {
+		if _, ok := s.workers[port]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		w := &worker{
+			cancel:     cancel,
+			done:       make(chan struct{}),
+			forward:    f,
+			kubeconfig: kubeconfig,
+			status:     Status{Forward: f, State: Starting, Since: s.now()},
+		}
+		s.workers[port] = w
+		go s.run(ctx, w)
+	}
+	s.notify()
+}
+
+// Get the status of every declared forward in the order
+// they were declared
+func (s *Supervisor) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]Status, 0, len(s.declared))
+	for _, f := range s.declared {
+		if w, ok := s.workers[f.LocalPort]; ok && w.forward == f {
+			statuses = append(statuses, w.status)
+			continue
+		}
+		statuses = append(statuses, Status{Forward: f, State: Stopped})
+	}
+	return statuses
+}
+
+// Stop every forward and wait for them to exit
+func (s *Supervisor) Stop() {
+	s.Sync("", nil)
+}
+
+func (s *Supervisor) run(ctx context.Context, w *worker) {
+	defer close(w.done)
+
+	backoff := minBackoff
+	for {
+		s.setState(w, Starting, "")
+		var readyAt atomic.Int64
+		err := s.forwarder.Forward(ctx, w.kubeconfig, w.forward, func() {
+			readyAt.Store(s.now().UnixNano())
+			s.setState(w, Running, "")
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if at := readyAt.Load(); at > 0 && s.now().Sub(time.Unix(0, at)) >= stableAfter {
+			backoff = minBackoff
+		}
+
+		reason := "connection dropped"
+		if err != nil {
+			reason = err.Error()
+		}
+		s.mu.Lock()
+		w.status.Restarts++
+		s.mu.Unlock()
+		s.setState(w, Restarting, reason)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.after(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+func (s *Supervisor) setState(w *worker, state State, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if w.status.State != state {
+		w.status.Since = s.now()
+	}
+	w.status.State = state
+	if reason != "" {
+		w.status.Error = reason
+	}
+	s.notify()
+}
+
+// notify must be called with the lock held
+func (s *Supervisor) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package portforward
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mproffitt/bmx/pkg/helpers"
+)
+
+// A single call to the fake forwarder
+//
+// The forward runs until drop is sent the error to return
+type call struct {
+	forward helpers.Forward
+	ready   func()
+	drop    chan error
+}
+
+// fakeForwarder hands each forward to the test to control
+type fakeForwarder struct {
+	calls chan *call
+}
+
+func (f *fakeForwarder) Forward(ctx context.Context, _ string, forward helpers.Forward, ready func()) error {
+	c := &call{forward: forward, ready: ready, drop: make(chan error)}
+	f.calls <- c
+	select {
+	case err := <-c.drop:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fakeClock records each backoff and only releases it when told to
+type fakeClock struct {
+	mu      sync.Mutex
+	current time.Time
+	waits   chan time.Duration
+	release chan time.Time
+}
+
+func (c *fakeClock) after(d time.Duration) <-chan time.Time {
+	c.waits <- d
+	return c.release
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current = c.current.Add(d)
+}
+
+func newTestSupervisor() (*Supervisor, *fakeForwarder, *fakeClock) {
+	forwarder := &fakeForwarder{calls: make(chan *call)}
+	clock := &fakeClock{
+		current: time.Now(),
+		waits:   make(chan time.Duration),
+		release: make(chan time.Time),
+	}
+	s := NewSupervisor(forwarder)
+	s.after, s.now = clock.after, clock.now
+	return s, forwarder, clock
+}
+
+var testForward = helpers.Forward{
+	Context:    "kind-kind",
+	Target:     "svc/web",
+	LocalPort:  8080,
+	RemotePort: "80",
+}
+
+func (f *fakeForwarder) next(t *testing.T) *call {
+	t.Helper()
+	select {
+	case c := <-f.calls:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("forward was not started")
+	}
+	return nil
+}
+
+func (c *fakeClock) backoff(t *testing.T) time.Duration {
+	t.Helper()
+	select {
+	case d := <-c.waits:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("forward did not back off")
+	}
+	return 0
+}
+
+// Wait for the first forward to match the condition
+func waitForStatus(t *testing.T, s *Supervisor, condition func(Status) bool) Status {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		if statuses := s.Status(); len(statuses) > 0 && condition(statuses[0]) {
+			return statuses[0]
+		}
+		select {
+		case <-s.Changed():
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatalf("timed out waiting for status, have %+v", s.Status())
+		}
+	}
+}
+
+func TestSupervisorRestartsDroppedForwards(t *testing.T) {
+	s, forwarder, clock := newTestSupervisor()
+	defer s.Stop()
+	s.Sync("config", []helpers.Forward{testForward})
+
+	first := forwarder.next(t)
+	if first.forward != testForward {
+		t.Fatalf("started %+v, want %+v", first.forward, testForward)
+	}
+	first.ready()
+	waitForStatus(t, s, func(st Status) bool { return st.State == Running })
+
+	first.drop <- errors.New("lost connection to pod")
+	if d := clock.backoff(t); d != minBackoff {
+		t.Errorf("backoff = %s, want %s", d, minBackoff)
+	}
+	status := waitForStatus(t, s, func(st Status) bool { return st.State == Restarting })
+	if status.Restarts != 1 || status.Error != "lost connection to pod" {
+		t.Errorf("status = %+v, want 1 restart for the lost connection", status)
+	}
+
+	clock.release <- time.Time{}
+	second := forwarder.next(t)
+	second.ready()
+	status = waitForStatus(t, s, func(st Status) bool { return st.State == Running })
+	if status.Restarts != 1 {
+		t.Errorf("restarts = %d, want 1", status.Restarts)
+	}
+}
+
+func TestSupervisorBacksOff(t *testing.T) {
+	s, forwarder, clock := newTestSupervisor()
+	defer s.Stop()
+	s.Sync("config", []helpers.Forward{testForward})
+
+	// forwards that drop straight away back off up to the limit
+	want := []time.Duration{
+		1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second,
+		16 * time.Second, maxBackoff, maxBackoff,
+	}
+	for _, expected := range want {
+		forwarder.next(t).drop <- nil
+		if d := clock.backoff(t); d != expected {
+			t.Fatalf("backoff = %s, want %s", d, expected)
+		}
+		clock.release <- time.Time{}
+	}
+
+	// one that drops as soon as it is ready keeps backing off
+	c := forwarder.next(t)
+	c.ready()
+	clock.advance(stableAfter - time.Second)
+	c.drop <- nil
+	if d := clock.backoff(t); d != maxBackoff {
+		t.Errorf("backoff after a short run = %s, want %s", d, maxBackoff)
+	}
+	clock.release <- time.Time{}
+
+	// one that stayed up long enough starts again from the minimum
+	c = forwarder.next(t)
+	c.ready()
+	clock.advance(stableAfter)
+	c.drop <- nil
+	if d := clock.backoff(t); d != minBackoff {
+		t.Errorf("backoff after a stable run = %s, want %s", d, minBackoff)
+	}
+	status := waitForStatus(t, s, func(st Status) bool { return st.State == Restarting })
+	if status.Restarts != len(want)+2 {
+		t.Errorf("restarts = %d, want %d", status.Restarts, len(want)+2)
+	}
+	if status.Error != "connection dropped" {
+		t.Errorf("error = %q, want connection dropped", status.Error)
+	}
+}
+
+func TestSupervisorStop(t *testing.T) {
+	s, forwarder, clock := newTestSupervisor()
+	s.Sync("config", []helpers.Forward{testForward})
+	forwarder.next(t).ready()
+	waitForStatus(t, s, func(st Status) bool { return st.State == Running })
+
+	// marking the forward stopped cancels it
+	stopped := testForward
+	stopped.Stopped = true
+	s.Sync("config", []helpers.Forward{stopped})
+	if status := s.Status()[0]; status.State != Stopped {
+		t.Errorf("state = %s, want %s", status.State, Stopped)
+	}
+
+	// a forward waiting to restart is stopped without starting again
+	s.Sync("config", []helpers.Forward{testForward})
+	forwarder.next(t).drop <- nil
+	clock.backoff(t)
+
+	done := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stop did not return")
+	}
+	if statuses := s.Status(); len(statuses) != 0 {
+		t.Errorf("statuses = %+v, want none after stop", statuses)
+	}
+	select {
+	case c := <-forwarder.calls:
+		t.Errorf("forward %+v was started after stop", c.forward)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package forwards
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mproffitt/bmx/pkg/components/icons"
+	"github.com/mproffitt/bmx/pkg/components/toast"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/portforward"
+	"github.com/mproffitt/bmx/pkg/theme"
+)
+
+const (
+	defaultWidth = 72
+	visibleRows  = 8
+)
+
+// Model is an overlay showing the port-forwards for a session
+//
+// New forwards are added against the context that was selected
+// in the context panel when the overlay was opened
+type Model struct {
+	adding    bool
+	context   string
+	cursor    int
+	err       error
+	input     textinput.Model
+	namespace string
+	session   string
+	statuses  []portforward.Status
+	styles    styles
+	width     int
+}
+
+type styles struct {
+	cursor  lipgloss.Style
+	dim     lipgloss.Style
+	error   lipgloss.Style
+	input   lipgloss.Style
+	overlay lipgloss.Style
+	states  map[portforward.State]lipgloss.Style
+	title   lipgloss.Style
+}
+
+// Create a new port-forward overlay for the session
+func New(session, context, namespace string) *Model {
+	m := Model{
+		context:   context,
+		input:     textinput.New(),
+		namespace: namespace,
+		session:   session,
+		styles: styles{
+			cursor: lipgloss.NewStyle().Foreground(theme.Colours.BrightBlue).Bold(true),
+			dim:    lipgloss.NewStyle().Foreground(theme.Colours.BrightBlack),
+			error:  lipgloss.NewStyle().Foreground(theme.Colours.Red),
+			input: lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder(), true).
+				BorderForeground(theme.Colours.Green),
+			overlay: lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder(), true).
+				BorderForeground(theme.Colours.Black).
+				Padding(0, 1),
+			states: map[portforward.State]lipgloss.Style{
+				portforward.Running:    lipgloss.NewStyle().Foreground(theme.Colours.Green),
+				portforward.Starting:   lipgloss.NewStyle().Foreground(theme.Colours.Yellow),
+				portforward.Restarting: lipgloss.NewStyle().Foreground(theme.Colours.Red),
+				portforward.Stopped:    lipgloss.NewStyle().Foreground(theme.Colours.BrightBlack),
+			},
+			title: lipgloss.NewStyle().Padding(0, 2).
+				Border(lipgloss.RoundedBorder(), false, false, true, false).
+				Foreground(theme.Colours.Yellow),
+		},
+		width: defaultWidth,
+	}
+	m.input.Placeholder = "service/name [local:]remote"
+	m.input.Width = m.width - 8
+	m.Reload()
+	return &m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) GetSize() (int, int) {
+	return m.width, lipgloss.Height(m.View())
+}
+
+func (m *Model) Overlay() helpers.UseOverlay {
+	return m
+}
+
+// The overlay has an active dialog while a forward is being added
+func (m *Model) HasActiveDialog() bool {
+	return m.adding
+}
+
+// Read the latest status of each forward
+func (m *Model) Reload() {
+	m.statuses, m.err = portforward.Statuses(m.session)
+	m.cursor = min(m.cursor, max(0, len(m.statuses)-1))
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keymsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.adding {
+		switch keymsg.String() {
+		case "esc":
+			m.adding = false
+			m.input.Blur()
+			m.input.Reset()
+			return m, nil
+		case "enter":
+			return m, m.add()
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	switch keymsg.String() {
+	case "up", "k":
+		m.cursor = max(0, m.cursor-1)
+	case "down", "j":
+		m.cursor = min(max(0, len(m.statuses)-1), m.cursor+1)
+	case "a":
+		m.adding = true
+		m.err = nil
+		return m, m.input.Focus()
+	case " ":
+		if f, ok := m.selected(); ok {
+			action := "Stopped "
+			if f.Stopped {
+				action = "Started "
+			}
+			return m, m.apply(action+portforward.Describe(f), portforward.SetStopped(m.session, f.LocalPort, !f.Stopped))
+		}
+	case "x", "delete":
+		if f, ok := m.selected(); ok {
+			return m, m.apply("Removed "+portforward.Describe(f), portforward.Remove(m.session, f.LocalPort))
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) selected() (helpers.Forward, bool) {
+	if len(m.statuses) == 0 {
+		return helpers.Forward{}, false
+	}
+	return m.statuses[m.cursor].Forward, true
+}
+
+func (m *Model) add() tea.Cmd {
+	fields := strings.Fields(m.input.Value())
+	if len(fields) != 2 {
+		m.err = fmt.Errorf("enter a target and ports, for example %q", "service/name 8443:https")
+		return nil
+	}
+	f, err := portforward.Parse(m.context, m.namespace, fields[0], fields[1])
+	if err != nil {
+		m.err = err
+		return nil
+	}
+	m.adding = false
+	m.input.Blur()
+	m.input.Reset()
+	return m.apply("Added "+portforward.Describe(f), portforward.Add(m.session, f))
+}
+
+func (m *Model) apply(message string, err error) tea.Cmd {
+	m.Reload()
+	if err != nil {
+		return helpers.NewErrorCmd(err)
+	}
+	return toast.NewToastCmd(toast.Info, message)
+}
+
+func (m *Model) View() string {
+	width := m.width - 4
+	title := m.styles.title.Render("Port forwards : " + m.session)
+
+	parts := []string{title, m.viewForwards(width)}
+	if m.err != nil {
+		parts = append(parts, m.styles.error.Width(width).Render(m.err.Error()))
+	}
+	if m.adding {
+		parts = append(parts,
+			m.styles.dim.Width(width).Render(ansi.Truncate(
+				"in "+m.context+" ("+m.namespace+")", width, string(icons.Ellipsis))),
+			m.styles.input.Width(width-2).Render(m.input.View()))
+	} else {
+		parts = append(parts, m.styles.dim.Width(width).Render(
+			"a add  "+icons.Space+" start/stop  x remove"))
+	}
+	return m.styles.overlay.Width(m.width).Render(
+		lipgloss.JoinVertical(lipgloss.Center, parts...))
+}
+
+func (m *Model) viewForwards(width int) string {
+	if len(m.statuses) == 0 {
+		return m.styles.dim.Width(width).Render("No forwards for this session")
+	}
+
+	start := max(0, min(m.cursor-visibleRows/2, len(m.statuses)-visibleRows))
+	end := min(len(m.statuses), start+visibleRows)
+
+	rows := make([]string, 0)
+	for i := start; i < end; i++ {
+		s := m.statuses[i]
+
+		style := lipgloss.NewStyle()
+		if i == m.cursor {
+			style = m.styles.cursor
+		}
+		state := m.styles.states[s.State].Render(fmt.Sprintf("%-10s", s.State))
+		line := state + " " + style.Render(ansi.Truncate(
+			portforward.Describe(s.Forward), width-11, string(icons.Ellipsis)))
+
+		detail := fmt.Sprintf("    %s (%s)", s.Forward.Context, s.Forward.Namespace)
+		if s.State != portforward.Stopped && !s.Since.IsZero() {
+			detail += " for " + time.Since(s.Since).Round(time.Second).String()
+		}
+		if s.Restarts > 0 {
+			detail += fmt.Sprintf(", %d restarts", s.Restarts)
+		}
+		if s.Error != "" && s.State != portforward.Running {
+			detail += ": " + s.Error
+		}
+		rows = append(rows, line+"\n"+m.styles.dim.Render(ansi.Truncate(detail, width, string(icons.Ellipsis))))
+	}
+
+	if len(m.statuses) > visibleRows {
+		rows = append(rows, m.styles.dim.Render(
+			fmt.Sprintf("%d/%d", m.cursor+1, len(m.statuses))))
+	}
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(rows, "\n"))
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package providers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mproffitt/bmx/pkg/aws"
+	"github.com/mproffitt/bmx/pkg/tmux"
+)
+
+// AWS is the name of the AWS profile provider
+const AWS = "aws"
+
+const (
+	awsProfile = "AWS_PROFILE"
+	awsRegion  = "AWS_REGION"
+)
+
+// The AWS provider sets AWS_PROFILE for the session from the
+// profiles in the AWS config and credentials files
+//
+// The region of a profile can be overridden for the session
+// by choosing one as its option, which sets AWS_REGION
+type awsProvider struct{}
+
+func init() {
+	Register(awsProvider{})
+}
+
+func (awsProvider) Name() string {
+	return AWS
+}
+
+func (awsProvider) Title() string {
+	return "AWS"
+}
+
+func (awsProvider) EnvVar() string {
+	return awsProfile
+}
+
+func (awsProvider) Variables() []string {
+	return []string{awsRegion}
+}
+
+func (awsProvider) Contexts(session string) ([]Context, error) {
+	profiles, err := aws.Profiles()
+	if err != nil {
+		return nil, err
+	}
+
+	current := tmux.GetTmuxEnvVar(session, awsProfile)
+	region := tmux.GetTmuxEnvVar(session, awsRegion)
+	contexts := make([]Context, 0, len(profiles))
+	for _, p := range profiles {
+		c := Context{
+			Name:    p.Name,
+			Current: p.Name == current,
+		}
+		if c.Current && region != "" {
+			p.Region = region
+		}
+		details := make([]string, 0, 2)
+		for _, d := range []string{p.Region, p.Source} {
+			if d != "" {
+				details = append(details, d)
+			}
+		}
+		c.Detail = strings.Join(details, " · ")
+		contexts = append(contexts, c)
+	}
+	return contexts, nil
+}
+
+// Selecting a profile drops any region chosen for the
+// previous one so the profiles own region applies
+func (awsProvider) SetCurrent(session, context string) error {
+	if err := tmux.SetSessionEnvironment(session, awsProfile, context); err != nil {
+		return err
+	}
+	if tmux.GetTmuxEnvVar(session, awsRegion) == "" {
+		return nil
+	}
+	return tmux.UnsetSessionEnvironment(session, awsRegion)
+}
+
+// The profiles own region is offered even if it is not a known region
+func (awsProvider) Options(session, context string) (*Options, error) {
+	profiles, err := aws.Profiles()
+	if err != nil {
+		return nil, err
+	}
+	regions := slices.Clone(aws.Regions)
+	for _, p := range profiles {
+		if p.Name == context && p.Region != "" && !slices.Contains(regions, p.Region) {
+			regions = append(regions, p.Region)
+		}
+	}
+	return &Options{
+		Title:    "Region for " + context,
+		Values:   regions,
+		FreeText: true,
+	}, nil
+}
+
+// Choosing a region also makes the profile current
+func (awsProvider) SetOption(session, context, option string) error {
+	if err := tmux.SetSessionEnvironment(session, awsProfile, context); err != nil {
+		return err
+	}
+	return tmux.SetSessionEnvironment(session, awsRegion, option)
+}
+
+// The profiles come from the AWS files and the selection
+// from the session environment
+func (awsProvider) Fingerprint(session string) string {
+	return strings.Join([]string{
+		filesFingerprint(aws.ConfigFile(), aws.CredentialsFile()),
+		tmux.GetTmuxEnvVar(session, awsProfile),
+		tmux.GetTmuxEnvVar(session, awsRegion),
+	}, ",")
+}
+
+// Profiles are left for the AWS cli to manage
+func (awsProvider) Delete(session string, contexts []string) error {
+	return fmt.Errorf("deleting AWS profiles %w", ErrNotSupported)
+}
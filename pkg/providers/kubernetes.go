@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package providers
+
+import (
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/tmux"
+)
+
+// Kubernetes is the name of the kubernetes context provider
+const Kubernetes = "kubernetes"
+
+// The kubernetes provider works on the session kubeconfig
+//
+// Unlike other providers the session variable points at a file
+// and the current context is held inside that file. It is enabled
+// by `manageSessionKubeContext` rather than the providers list
+type kubeProvider struct{}
+
+func init() {
+	Register(kubeProvider{})
+}
+
+func (kubeProvider) Name() string {
+	return Kubernetes
+}
+
+func (kubeProvider) Title() string {
+	return "Kubernetes"
+}
+
+func (kubeProvider) EnvVar() string {
+	return "KUBECONFIG"
+}
+
+func (kubeProvider) Contexts(session string) ([]Context, error) {
+	list, err := kubernetes.KubeContextList(true, Kubeconfig(session))
+	if err != nil {
+		return nil, err
+	}
+	contexts := make([]Context, 0, len(list))
+	for _, c := range list {
+		contexts = append(contexts, Context{
+			Name:    c.Name,
+			Detail:  c.Namespace,
+			Current: c.IsCurrentContext,
+		})
+	}
+	return contexts, nil
+}
+
+func (kubeProvider) SetCurrent(session, context string) error {
+	return kubernetes.SetCurrentContext(context, Kubeconfig(session))
+}
+
+// Namespaces come from the cache, which is refreshed whenever
+// the namespace chooser in the kubernetes panel is opened
+func (kubeProvider) Options(session, context string) (*Options, error) {
+	options := Options{
+		Title:    "Namespaces",
+		Values:   kubernetes.CachedNamespaces(context, Kubeconfig(session)).All(),
+		FreeText: true,
+	}
+	return &options, nil
+}
+
+func (kubeProvider) SetOption(session, context, option string) error {
+	kubeconfig := Kubeconfig(session)
+	results := kubernetes.SetNamespaces([]string{context}, option, kubeconfig)
+	if err := results[context]; err != nil {
+		return err
+	}
+	return kubernetes.RecordNamespace(context, kubeconfig, option)
+}
+
+func (kubeProvider) Delete(session string, contexts []string) error {
+	results := kubernetes.DeleteContexts(contexts, Kubeconfig(session))
+	errs := make([]error, 0)
+	for _, name := range slices.Sorted(maps.Keys(results)) {
+		errs = append(errs, results[name])
+	}
+	return errors.Join(errs...)
+}
+
+// The kubeconfig files are written by kubectl and login tools
+// as well as bmx so they are watched rather than the session
+func (kubeProvider) Fingerprint(session string) string {
+	return kubernetes.ConfigFingerprint(Kubeconfig(session))
+}
+
+// Kubeconfig gets the kubeconfig used by a session, falling back
+// to the default file if the session has none set
+func Kubeconfig(session string) string {
+	kubeconfig := tmux.GetTmuxEnvVar(session, "KUBECONFIG")
+	if kubeconfig == "" {
+		kubeconfig = kubernetes.DefaultConfigFile()
+	}
+	return kubeconfig
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package providers
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/charmbracelet/log"
+	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/tmux"
+)
+
+// ErrNotSupported is returned by providers for actions their tool
+// has no equivalent of
+var ErrNotSupported = errors.New("not supported by this provider")
+
+// Context is a single entry a provider can make current for a session
+//
+// Context implements `bubbles::list.DefaultItem`
+type Context struct {
+	Name    string
+	Detail  string
+	Current bool
+}
+
+// Get the title of this list item
+func (c Context) Title() string {
+	return c.Name
+}
+
+// Get the description of this list item
+func (c Context) Description() string {
+	return c.Detail
+}
+
+// The value used when filtering the list
+func (c Context) FilterValue() string { return c.Name }
+
+// Options are the sub-options offered for a context, for example the
+// namespaces of a kubernetes context or the regions for a profile
+type Options struct {
+	Title    string
+	Values   []string
+	FreeText bool
+}
+
+// Provider is a tool that has a current context per session
+//
+// Each session keeps its selection in the tmux session environment
+// under EnvVar so new panes pick it up
+type Provider interface {
+	// The name the provider is enabled by in the config
+	Name() string
+
+	// The title shown on the providers tab
+	Title() string
+
+	// The variable holding the selection in the session environment
+	EnvVar() string
+
+	// List the contexts available to the session
+	Contexts(session string) ([]Context, error)
+
+	// Make the named context current for the session
+	SetCurrent(session, context string) error
+
+	// Get the sub-options of a context. A nil result means
+	// the provider has none
+	Options(session, context string) (*Options, error)
+
+	// Apply the chosen sub-option to a context
+	SetOption(session, context, option string) error
+
+	// Delete the named contexts
+	Delete(session string, contexts []string) error
+
+	// Identify the state of the contexts available to the session.
+	// It must be cheap to build and change whenever Contexts would
+	// return something different
+	Fingerprint(session string) string
+}
+
+// Variables can be implemented by providers that keep more
+// than the selection in the session environment
+type Variables interface {
+	Variables() []string
+}
+
+var registry []Provider
+
+// Register a provider so it can be enabled in the config
+//
+// Providers are shown in the order they are registered
+func Register(p Provider) {
+	registry = append(registry, p)
+}
+
+// Get a registered provider by name
+func Get(name string) Provider {
+	for _, p := range registry {
+		if p.Name() == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Enabled returns the providers enabled in the config
+//
+// Kubernetes is enabled by `manageSessionKubeContext` and always comes
+// first, any other provider is enabled by listing it under `providers`
+func Enabled(c *config.Config) []Provider {
+	enabled := make([]Provider, 0)
+	if c.ManageSessionKubeContext {
+		enabled = append(enabled, Get(Kubernetes))
+	}
+	for _, p := range registry {
+		if p.Name() == Kubernetes || !slices.Contains(c.Providers, p.Name()) {
+			continue
+		}
+		enabled = append(enabled, p)
+	}
+	for _, name := range c.Providers {
+		if name != Kubernetes && Get(name) == nil {
+			log.Warn("unknown context provider", "name", name)
+		}
+	}
+	return enabled
+}
+
+// SessionEnvironment gets the values of all providers set in the
+// session environment so they can be saved with the session
+//
+// The kubeconfig is not included as it is rebuilt from the
+// session layers when the session is loaded
+func SessionEnvironment(session string) map[string]string {
+	env := make(map[string]string)
+	for _, p := range registry {
+		if p.Name() == Kubernetes {
+			continue
+		}
+		variables := []string{p.EnvVar()}
+		if v, ok := p.(Variables); ok {
+			variables = append(variables, v.Variables()...)
+		}
+		for _, variable := range variables {
+			if value := tmux.GetTmuxEnvVar(session, variable); value != "" {
+				env[variable] = value
+			}
+		}
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return env
+}
@@ -0,0 +1,419 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package contexts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mproffitt/bmx/pkg/components/dialog"
+	"github.com/mproffitt/bmx/pkg/components/icons"
+	"github.com/mproffitt/bmx/pkg/components/optionlist"
+	"github.com/mproffitt/bmx/pkg/components/overlay"
+	"github.com/mproffitt/bmx/pkg/components/toast"
+	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/providers"
+	"github.com/mproffitt/bmx/pkg/theme"
+)
+
+// Model is the context panel for any provider
+//
+// It lists the providers contexts for a session, sets the current
+// one and offers the sub-options of the selected context
+type Model struct {
+	cursor      int
+	err         error
+	fingerprint string
+	focused     bool
+	height      int
+	items       []providers.Context
+	keymap      *keyMap
+	options     tea.Model
+	optionFor   string
+	provider    providers.Provider
+	session     string
+	styles      styles
+	todelete    []string
+	width       int
+}
+
+type styles struct {
+	current lipgloss.Style
+	cursor  lipgloss.Style
+	detail  lipgloss.Style
+	dim     lipgloss.Style
+	error   lipgloss.Style
+	normal  lipgloss.Style
+	focused lipgloss.Style
+	title   lipgloss.Style
+}
+
+type keyMap struct {
+	Delete    key.Binding
+	Down      key.Binding
+	Enter     key.Binding
+	KillPanel key.Binding
+	Space     key.Binding
+	Up        key.Binding
+}
+
+func (k *keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Enter, k.Space}
+}
+
+func (k *keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Enter},
+		{k.Space, k.Delete, k.KillPanel},
+	}
+}
+
+// Create a new panel for the provider
+func New(p providers.Provider) *Model {
+	m := Model{
+		keymap: &keyMap{
+			Delete: key.NewBinding(key.WithKeys("delete", "x"),
+				key.WithHelp("del/x", "Delete the current item")),
+			Down: key.NewBinding(key.WithKeys("down", "j"),
+				key.WithHelp(icons.Down, "move down")),
+			Enter: key.NewBinding(key.WithKeys("enter"),
+				key.WithHelp(icons.Enter, "Set current context")),
+			KillPanel: key.NewBinding(key.WithKeys("ctrl+c", "esc"),
+				key.WithHelp("esc", "Close overlays or quit")),
+			Space: key.NewBinding(key.WithKeys(" "),
+				key.WithHelp(icons.Space, "Change context options")),
+			Up: key.NewBinding(key.WithKeys("up", "k"),
+				key.WithHelp(icons.Up, "move up")),
+		},
+		provider: p,
+		styles: styles{
+			current: lipgloss.NewStyle().Foreground(theme.Colours.Green),
+			cursor:  lipgloss.NewStyle().Foreground(theme.Colours.BrightBlue).Bold(true),
+			detail:  lipgloss.NewStyle().Foreground(theme.Colours.BrightBlack),
+			dim:     lipgloss.NewStyle().Foreground(theme.Colours.Blue),
+			error:   lipgloss.NewStyle().Foreground(theme.Colours.Red),
+			normal: lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder(), true).
+				BorderForeground(theme.Colours.Black).
+				PaddingLeft(2).PaddingRight(2),
+			focused: lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder(), true).
+				BorderForeground(theme.Colours.Blue).
+				PaddingLeft(2).PaddingRight(2),
+			title: lipgloss.NewStyle().Foreground(theme.Colours.Yellow),
+		},
+	}
+	return &m
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) Blur() tea.Model {
+	m.focused = false
+	return m
+}
+
+func (m *Model) Focus() tea.Model {
+	m.focused = true
+	return m
+}
+
+func (m *Model) GetSize() (int, int) {
+	return m.width, m.height
+}
+
+// Set the size of the list inside the border
+//
+// Contexts are shown in a single column so columnWidth is unused
+func (m *Model) SetSize(width, height, columnWidth int) tea.Model {
+	m.width = width
+	m.height = height
+	return m
+}
+
+func (m *Model) Help() dialog.HelpEntry {
+	km := help.KeyMap(m.keymap)
+	return dialog.HelpEntry{
+		Keymap: &km,
+		Title:  m.provider.Title() + " Context Panel",
+		Help: "Sets the " + m.provider.Title() + " context used by the session.\n\n" +
+			"The choice is stored in " + m.provider.EnvVar() + " in the\n" +
+			"session environment and picked up by new panes",
+	}
+}
+
+// Get the provider shown by the panel
+func (m *Model) Provider() providers.Provider {
+	return m.provider
+}
+
+// Show the contexts for a session
+//
+// The list is only reloaded if the session has changed
+func (m *Model) SetSession(session string) tea.Model {
+	if session == m.session {
+		return m
+	}
+	m.session = session
+	m.cursor = 0
+	m.Reload()
+	return m
+}
+
+// Reload the contexts from the provider
+//
+// The selected context stays selected if it still exists
+func (m *Model) Reload() {
+	var selected string
+	if m.cursor < len(m.items) {
+		selected = m.items[m.cursor].Name
+	}
+
+	m.fingerprint = m.provider.Fingerprint(m.session)
+	m.items, m.err = m.provider.Contexts(m.session)
+	m.cursor = min(m.cursor, max(0, len(m.items)-1))
+	for i, item := range m.items {
+		if item.Name == selected {
+			m.cursor = i
+			break
+		}
+	}
+}
+
+// Get the fingerprint of the provider when the contexts were loaded
+func (m *Model) Fingerprint() string {
+	return m.fingerprint
+}
+
+// Select the named context
+//
+// If the context is not in the list the selection is unchanged
+func (m *Model) SelectContext(name string) tea.Model {
+	for i, item := range m.items {
+		if item.Name == name {
+			m.cursor = i
+			break
+		}
+	}
+	return m
+}
+
+func (m *Model) Overlay() helpers.UseOverlay {
+	if m.options != nil {
+		return m.options.(helpers.UseOverlay).Overlay()
+	}
+	if len(m.todelete) > 0 {
+		builder := strings.Builder{}
+		builder.WriteString("Are you sure you want to delete\n")
+		builder.WriteString(lipgloss.PlaceHorizontal(config.DialogWidth, lipgloss.Center,
+			lipgloss.NewStyle().
+				Bold(true).
+				Foreground(theme.Colours.BrightBlue).
+				Padding(1).
+				Render(strings.Join(m.todelete, "\n"))))
+		return dialog.NewConfirmDialog(builder.String(), config.DialogWidth).(helpers.UseOverlay)
+	}
+	return nil
+}
+
+func (m *Model) RequiresOverlay() bool {
+	return m.options != nil || len(m.todelete) > 0
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keymap.KillPanel):
+			m.options = nil
+			m.optionFor = ""
+			m.todelete = nil
+		case key.Matches(msg, m.keymap.Up):
+			m.cursor = max(0, m.cursor-1)
+		case key.Matches(msg, m.keymap.Down):
+			m.cursor = min(max(0, len(m.items)-1), m.cursor+1)
+		case key.Matches(msg, m.keymap.Enter):
+			return m, m.setCurrent()
+		case key.Matches(msg, m.keymap.Space):
+			return m, m.chooseOption()
+		case key.Matches(msg, m.keymap.Delete):
+			if name := m.selected(); name != "" {
+				m.todelete = []string{name}
+			}
+		}
+	case helpers.OverlayMsg:
+		switch value := msg.Message.(type) {
+		case string:
+			context := m.optionFor
+			m.options = nil
+			m.optionFor = ""
+			if context == "" {
+				break
+			}
+			if err := m.provider.SetOption(m.session, context, value); err != nil {
+				return m, helpers.NewErrorCmd(err)
+			}
+			m.Reload()
+			return m, tea.Batch(helpers.ReloadManagerCmd(),
+				toast.NewToastCmd(toast.Info, fmt.Sprintf("Set %s on %s", value, context)))
+		case dialog.Status:
+			todelete := m.todelete
+			m.todelete = nil
+			if value != dialog.Confirm || len(todelete) == 0 {
+				break
+			}
+			err := m.provider.Delete(m.session, todelete)
+			m.Reload()
+			if err != nil {
+				return m, helpers.NewErrorCmd(err)
+			}
+			return m, toast.NewToastCmd(toast.Warning, "Deleted "+strings.Join(todelete, ", "))
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) selected() string {
+	if m.cursor >= len(m.items) {
+		return ""
+	}
+	return m.items[m.cursor].Name
+}
+
+func (m *Model) setCurrent() tea.Cmd {
+	name := m.selected()
+	if name == "" {
+		return nil
+	}
+	err := m.provider.SetCurrent(m.session, name)
+	m.Reload()
+	if err != nil {
+		return helpers.NewErrorCmd(err)
+	}
+	// the session list may show the selection
+	return helpers.ReloadManagerCmd()
+}
+
+// Open the sub-options for the selected context
+func (m *Model) chooseOption() tea.Cmd {
+	name := m.selected()
+	if name == "" {
+		return nil
+	}
+	options, err := m.provider.Options(m.session, name)
+	if err != nil {
+		return helpers.NewErrorCmd(err)
+	}
+	if options == nil {
+		return toast.NewToastCmd(toast.Info, m.provider.Title()+" contexts have no options")
+	}
+	m.optionFor = name
+	m.options = optionlist.NewOptionModel(&choices{options})
+	return nil
+}
+
+func (m *Model) View() string {
+	style := m.styles.normal
+	if m.focused {
+		style = m.styles.focused
+	}
+
+	var body string
+	switch {
+	case m.err != nil:
+		body = m.styles.error.Width(m.width).Render(m.err.Error())
+	case len(m.items) == 0:
+		body = lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center,
+			m.styles.dim.Render("No "+m.provider.Title()+" contexts"))
+	default:
+		body = m.viewItems()
+	}
+	body = lipgloss.NewStyle().Width(m.width).Height(m.height).MaxHeight(m.height).Render(body)
+	doc := style.Render(body)
+
+	title := m.provider.Title()
+	for _, item := range m.items {
+		if item.Current {
+			title = m.provider.Title() + " : " + item.Name
+		}
+	}
+	title = m.styles.title.Render(ansi.Truncate(title, max(0, m.width), string(icons.Ellipsis)))
+	return overlay.PlaceOverlay(2, 0, title, doc, false)
+}
+
+// Render the visible contexts, keeping the cursor in view
+func (m *Model) viewItems() string {
+	var name int
+	for _, item := range m.items {
+		name = max(name, ansi.StringWidth(item.Name))
+	}
+	name = min(name, max(10, m.width/2))
+
+	visible := max(1, m.height)
+	start := max(0, min(m.cursor-visible/2, len(m.items)-visible))
+	end := min(len(m.items), start+visible)
+
+	rows := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		item := m.items[i]
+		marker := "  "
+		if item.Current {
+			marker = m.styles.current.Render(string(icons.CurrentIcon)) + " "
+		}
+		title := fmt.Sprintf("%-*s", name, ansi.Truncate(item.Name, name, string(icons.Ellipsis)))
+		if i == m.cursor && m.focused {
+			title = m.styles.cursor.Render(title)
+		}
+		detail := ansi.Truncate(item.Detail, max(0, m.width-name-3), string(icons.Ellipsis))
+		rows = append(rows, marker+title+" "+m.styles.detail.Render(detail))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// Provider options shown in the option list
+type choices struct {
+	options *providers.Options
+}
+
+func (c *choices) Title() string {
+	return c.options.Title
+}
+
+func (c *choices) FreeText() bool {
+	return c.options.FreeText
+}
+
+func (c *choices) Options() optionlist.Iterator {
+	return func(yield func(key int, val optionlist.Row) bool) {
+		for k, v := range c.options.Values {
+			if !yield(k, optionlist.Option{Value: v}) {
+				return
+			}
+		}
+	}
+}
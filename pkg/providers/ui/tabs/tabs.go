@@ -0,0 +1,297 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package tabs
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mproffitt/bmx/pkg/components/dialog"
+	"github.com/mproffitt/bmx/pkg/components/overlay"
+	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/kubernetes/ui/panel"
+	"github.com/mproffitt/bmx/pkg/providers"
+	"github.com/mproffitt/bmx/pkg/providers/ui/contexts"
+	"github.com/mproffitt/bmx/pkg/theme"
+)
+
+// Model shows a tab for each enabled context provider
+//
+// Every tab is refreshed by watching its own provider so changes
+// made outside of bmx show up whichever providers are enabled
+type Model struct {
+	active  int
+	focused bool
+	keymap  *keyMap
+	session string
+	styles  styles
+	tabs    []tab
+}
+
+type tab struct {
+	provider providers.Provider
+	panel    contextPanel
+}
+
+// The methods shared by all context panels
+type contextPanel interface {
+	helpers.UseOverlay
+	dialog.UseHelp
+	Blur() tea.Model
+	Fingerprint() string
+	Focus() tea.Model
+	Reload()
+	RequiresOverlay() bool
+	SelectContext(name string) tea.Model
+	SetSession(session string) tea.Model
+	SetSize(width, height, columnWidth int) tea.Model
+	View() string
+}
+
+// Creates the panel for a provider
+type newPanel func(c *config.Config, p providers.Provider, rows, cols, columnWidth int) contextPanel
+
+// Providers with a panel of their own. Kubernetes has actions
+// no other tool has an equivalent of, any other provider uses
+// the generic context panel
+var panels = map[string]newPanel{
+	providers.Kubernetes: func(c *config.Config, _ providers.Provider, rows, cols, columnWidth int) contextPanel {
+		return panel.NewKubectxPane(c, "", rows, cols, columnWidth)
+	},
+}
+
+func genericPanel(_ *config.Config, p providers.Provider, _, _, _ int) contextPanel {
+	return contexts.New(p)
+}
+
+type keyMap struct {
+	Next     key.Binding
+	Previous key.Binding
+}
+
+type styles struct {
+	active   lipgloss.Style
+	inactive lipgloss.Style
+}
+
+// Create the tabs for the providers enabled in the config
+//
+// rows, cols and columnWidth size panels that show
+// their contexts in columns
+func New(c *config.Config, session string, rows, cols, columnWidth int) *Model {
+	m := Model{
+		keymap: &keyMap{
+			Next: key.NewBinding(key.WithKeys("]"),
+				key.WithHelp("]", "Next provider")),
+			Previous: key.NewBinding(key.WithKeys("["),
+				key.WithHelp("[", "Previous provider")),
+		},
+		styles: styles{
+			active:   lipgloss.NewStyle().Foreground(theme.Colours.Yellow).Bold(true),
+			inactive: lipgloss.NewStyle().Foreground(theme.Colours.BrightBlack),
+		},
+	}
+	for _, p := range providers.Enabled(c) {
+		create, ok := panels[p.Name()]
+		if !ok {
+			create = genericPanel
+		}
+		m.tabs = append(m.tabs, tab{
+			provider: p,
+			panel:    create(c, p, rows, cols, columnWidth),
+		})
+	}
+	m.SetSession(session)
+	return &m
+}
+
+// Start watching every enabled provider for changes
+//
+// The tabs may not exist when the first checks arrive so the
+// receiver keeps them going until the tabs can take them over
+func WatchCmd(c *config.Config) tea.Cmd {
+	cmds := make([]tea.Cmd, 0)
+	for _, p := range providers.Enabled(c) {
+		cmds = append(cmds, providers.WatchCmd(p, ""))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *Model) Blur() tea.Model {
+	m.focused = false
+	if len(m.tabs) > 0 {
+		m.tabs[m.active].panel.Blur()
+	}
+	return m
+}
+
+func (m *Model) Focus() tea.Model {
+	m.focused = true
+	if len(m.tabs) > 0 {
+		m.tabs[m.active].panel.Focus()
+	}
+	return m
+}
+
+func (m *Model) GetSize() (int, int) {
+	if len(m.tabs) == 0 {
+		return 0, 0
+	}
+	return m.tabs[m.active].panel.GetSize()
+}
+
+func (m *Model) Overlay() helpers.UseOverlay {
+	if len(m.tabs) == 0 {
+		return nil
+	}
+	return m.tabs[m.active].panel.Overlay()
+}
+
+func (m *Model) RequiresOverlay() bool {
+	return len(m.tabs) > 0 && m.tabs[m.active].panel.RequiresOverlay()
+}
+
+func (m *Model) Help() dialog.HelpEntry {
+	if len(m.tabs) == 0 {
+		return dialog.HelpEntry{}
+	}
+	entry := m.tabs[m.active].panel.Help()
+	if len(m.tabs) > 1 {
+		entry.Help += "\n\nUse [ and ] to switch between providers"
+	}
+	return entry
+}
+
+// Set the size of every panel
+func (m *Model) SetSize(width, height, columnWidth int) tea.Model {
+	for _, t := range m.tabs {
+		t.panel.SetSize(width, height, columnWidth)
+	}
+	return m
+}
+
+// Show the contexts for a session in every panel
+func (m *Model) SetSession(session string) tea.Model {
+	m.session = session
+	for _, t := range m.tabs {
+		t.panel.SetSession(session)
+	}
+	return m
+}
+
+// Switch to the tab of a provider and select the named context
+func (m *Model) SelectContext(provider, name string) tea.Model {
+	for i, t := range m.tabs {
+		if t.provider.Name() == provider {
+			m.switchTo(i)
+			t.panel.SelectContext(name)
+		}
+	}
+	return m
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if len(m.tabs) == 0 {
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if len(m.tabs) > 1 && !m.RequiresOverlay() {
+			switch {
+			case key.Matches(msg, m.keymap.Next):
+				m.switchTo((m.active + 1) % len(m.tabs))
+				return m, nil
+			case key.Matches(msg, m.keymap.Previous):
+				m.switchTo((m.active + len(m.tabs) - 1) % len(m.tabs))
+				return m, nil
+			}
+		}
+	case helpers.OverlayMsg:
+		// overlays belong to the panel that is showing
+	case providers.WatchMsg:
+		return m, m.watch(msg)
+	default:
+		// Anything other than input may belong to a panel that
+		// isn't showing, such as namespaces arriving after the
+		// tab has been switched
+		cmds := make([]tea.Cmd, 0, len(m.tabs))
+		for _, t := range m.tabs {
+			_, cmd := t.panel.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+		return m, tea.Batch(cmds...)
+	}
+	_, cmd := m.tabs[m.active].panel.Update(msg)
+	return m, cmd
+}
+
+// Reload a panel if its provider changed since the panel was
+// loaded and keep watching the provider for the current session
+func (m *Model) watch(msg providers.WatchMsg) tea.Cmd {
+	for _, t := range m.tabs {
+		if t.provider.Name() != msg.Provider {
+			continue
+		}
+		if msg.Session == m.session && msg.Fingerprint != t.panel.Fingerprint() {
+			t.panel.Reload()
+		}
+		return providers.WatchCmd(t.provider, m.session)
+	}
+	return nil
+}
+
+// Show another tab, moving focus to it if the tabs are focused
+func (m *Model) switchTo(index int) {
+	m.tabs[m.active].panel.Blur()
+	m.active = index
+	if m.focused {
+		m.tabs[m.active].panel.Focus()
+	}
+}
+
+func (m *Model) View() string {
+	if len(m.tabs) == 0 {
+		return ""
+	}
+	doc := m.tabs[m.active].panel.View()
+	if len(m.tabs) == 1 {
+		return doc
+	}
+
+	names := make([]string, 0, len(m.tabs))
+	for i, t := range m.tabs {
+		style := m.styles.inactive
+		if i == m.active {
+			style = m.styles.active
+		}
+		names = append(names, style.Render(t.provider.Title()))
+	}
+	strip := " " + strings.Join(names, m.styles.inactive.Render(" │ ")) + " "
+	x := max(0, lipgloss.Width(doc)-lipgloss.Width(strip)-2)
+	return overlay.PlaceOverlay(x, 0, strip, doc, false)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package providers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// How often providers are checked for changes
+const watchInterval = 2 * time.Second
+
+// WatchMsg is sent each time a provider is checked for a session
+type WatchMsg struct {
+	Provider    string
+	Session     string
+	Fingerprint string
+}
+
+// Check a provider for changes after the watch interval
+//
+// Each provider is watched on its own so a change made outside
+// of bmx shows up whichever providers are enabled. The receiver
+// should call WatchCmd again to keep watching
+func WatchCmd(p Provider, session string) tea.Cmd {
+	return tea.Tick(watchInterval, func(time.Time) tea.Msg {
+		return WatchMsg{
+			Provider:    p.Name(),
+			Session:     session,
+			Fingerprint: p.Fingerprint(session),
+		}
+	})
+}
+
+// Build a fingerprint from the modification time and size of files
+func filesFingerprint(files ...string) string {
+	parts := make([]string, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			parts = append(parts, file+":missing")
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", file, info.ModTime().UnixNano(), info.Size()))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package repos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	giturl "github.com/kubescape/go-git-url"
+	git "gopkg.in/src-d/go-git.v4"
+)
+
+// DefaultCloneLayout is where repositories are cloned to under the
+// first configured path when no layout is set
+const DefaultCloneLayout = "{host}/{owner}/{repo}"
+
+// scp style urls such as git@github.com:owner/repo.git
+var scpURL = regexp.MustCompile(`^[\w.-]+@[\w.-]+:[^/]`)
+
+// IsURL reports whether the value looks like a git remote rather
+// than the name of a repository
+func IsURL(value string) bool {
+	value = strings.TrimSpace(value)
+	for _, scheme := range []string{"https://", "http://", "ssh://", "git://", "file://"} {
+		if strings.HasPrefix(value, scheme) {
+			return true
+		}
+	}
+	return scpURL.MatchString(value)
+}
+
+// ParseURL splits a git remote into its host, owner and repository name
+//
+// Hosts known to go-git-url are parsed by it, anything else is read
+// from the url path. Local `file://` remotes have the host `local`
+// and the directory holding the repository as the owner
+func ParseURL(remote string) (host, owner, repo string, err error) {
+	remote = strings.TrimSpace(remote)
+	if gitURL, err := giturl.NewGitURL(remote); err == nil {
+		return gitURL.GetHostName(), gitURL.GetOwnerName(), gitURL.GetRepoName(), nil
+	}
+
+	var path string
+	switch {
+	case scpURL.MatchString(remote):
+		at := strings.Index(remote, "@")
+		host, path, _ = strings.Cut(remote[at+1:], ":")
+	default:
+		u, err := url.Parse(remote)
+		if err != nil {
+			return "", "", "", err
+		}
+		host, path = u.Hostname(), u.Path
+		if u.Scheme == "file" {
+			host = "local"
+		}
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	repo = strings.TrimSuffix(segments[len(segments)-1], ".git")
+	if host == "" || repo == "" || len(segments) < 2 {
+		return "", "", "", fmt.Errorf("cannot find the owner and repository in %q", remote)
+	}
+	owner = strings.Join(segments[:len(segments)-1], "/")
+	if host == "local" {
+		// the rest of a local path says nothing about the owner
+		owner = segments[len(segments)-2]
+	}
+	return host, owner, repo, nil
+}
+
+// ClonePath gives the directory a remote is cloned to under root
+//
+// The layout may contain `{host}`, `{owner}` and `{repo}`. The result
+// must stay under root
+func ClonePath(root, layout, remote string) (string, error) {
+	host, owner, repo, err := ParseURL(remote)
+	if err != nil {
+		return "", err
+	}
+	if layout == "" {
+		layout = DefaultCloneLayout
+	}
+	path := strings.NewReplacer(
+		"{host}", host,
+		"{owner}", owner,
+		"{repo}", repo,
+	).Replace(layout)
+
+	path = filepath.Join(root, path)
+	if path == filepath.Clean(root) || !under(path, []string{root}) {
+		return "", fmt.Errorf("%q is not inside %q", path, root)
+	}
+	return path, nil
+}
+
+// CloneMsg reports the progress of a clone
+//
+// Progress is the latest line sent by the remote. Once Done is set
+// Err holds the result of the clone
+type CloneMsg struct {
+	Cloner   *Cloner
+	Progress string
+	Done     bool
+	Err      error
+}
+
+// Cloner clones a repository in the background
+type Cloner struct {
+	Path     string
+	URL      string
+	cancel   context.CancelFunc
+	changed  chan struct{}
+	done     chan struct{}
+	err      error
+	mu       sync.Mutex
+	progress string
+}
+
+// Clone the remote into path in the background
+//
+// A repository that already exists at path is used as it is. The
+// directory is removed again if the clone fails or is cancelled and
+// bmx created it
+func Clone(remote, path string) *Cloner {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := Cloner{
+		Path:    path,
+		URL:     strings.TrimSpace(remote),
+		cancel:  cancel,
+		changed: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(c.done)
+		defer cancel()
+		if _, err := os.Stat(filepath.Join(path, DefaultPattern)); err == nil {
+			return
+		}
+
+		_, err := os.Stat(path)
+		created := errors.Is(err, fs.ErrNotExist)
+
+		_, err = git.PlainCloneContext(ctx, path, false, &git.CloneOptions{
+			URL:      c.URL,
+			Progress: &c,
+		})
+		if err != nil && created {
+			_ = os.RemoveAll(path)
+		}
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+	}()
+	return &c
+}
+
+// Cancel stops the clone
+func (c *Cloner) Cancel() {
+	c.cancel()
+}
+
+// Write receives the progress from the remote
+//
+// Progress lines are separated by carriage returns so only
+// the last complete line is kept
+func (c *Cloner) Write(p []byte) (int, error) {
+	lines := strings.FieldsFunc(string(p), func(r rune) bool {
+		return r == '\r' || r == '\n'
+	})
+	if len(lines) == 0 {
+		return len(p), nil
+	}
+
+	c.mu.Lock()
+	c.progress = strings.TrimSpace(lines[len(lines)-1])
+	c.mu.Unlock()
+	select {
+	case c.changed <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// Next returns a command that waits for more progress
+//
+// Once the clone is done the final message has Done set and
+// Next should not be called again
+func (c *Cloner) Next() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case <-c.done:
+		case <-c.changed:
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		msg := CloneMsg{
+			Cloner:   c,
+			Progress: c.progress,
+		}
+		select {
+		case <-c.done:
+			msg.Done = true
+			msg.Err = c.err
+		default:
+		}
+		return msg
+	}
+}
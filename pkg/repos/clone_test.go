@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package repos
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Create a bare repository at <dir>/<owner>/<name>.git to clone from
+// and return its file url
+func newBareRepo(t *testing.T, owner, name string) string {
+	t.Helper()
+	source := newTestRepo(t)
+	bare := filepath.Join(t.TempDir(), owner, name+".git")
+	runGit(t, source, "clone", "-q", "--bare", source, bare)
+	return "file://" + bare
+}
+
+// Wait for the clone to finish, following its progress
+func waitForClone(t *testing.T, c *Cloner) error {
+	t.Helper()
+	for {
+		msg := c.Next()().(CloneMsg)
+		if msg.Cloner != c {
+			t.Fatal("message is from another clone")
+		}
+		if msg.Done {
+			return msg.Err
+		}
+	}
+}
+
+func TestCloneFileRemote(t *testing.T) {
+	remote := newBareRepo(t, "Owner", "Project")
+	root := t.TempDir()
+
+	path, err := ClonePath(root, "", remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, "local", "Owner", "Project"); path != want {
+		t.Fatalf("clone path = %q, want %q", path, want)
+	}
+
+	if err := waitForClone(t, Clone(remote, path)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "README.md")); err != nil {
+		t.Errorf("working tree was not checked out: %v", err)
+	}
+
+	repo, err := open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.Url != remote || repo.Name != "project" || repo.Owner != "owner" {
+		t.Errorf("repository = %+v, want project owned by owner from %q", repo, remote)
+	}
+	if status, err := ReadStatus(path); err != nil || status.Branch != "main" || !status.Upstream {
+		t.Errorf("status = %+v (error %v), want main tracking its remote", status, err)
+	}
+
+	// an existing clone is used as it is
+	marker := filepath.Join(path, "local.txt")
+	writeFile(t, marker, "keep\n")
+	if err := waitForClone(t, Clone(remote, path)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("existing clone was replaced: %v", err)
+	}
+}
+
+func TestCloneFailureRemovesDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "local", "owner", "missing")
+	remote := "file://" + filepath.Join(t.TempDir(), "owner", "missing.git")
+
+	if err := waitForClone(t, Clone(remote, path)); err == nil {
+		t.Fatal("expected cloning a missing remote to fail")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("clone directory was left behind: %v", err)
+	}
+}
+
+func TestClonePathStaysUnderRoot(t *testing.T) {
+	root := t.TempDir()
+	if path, err := ClonePath(root, "../{repo}", "https://github.com/owner/repo"); err == nil {
+		t.Errorf("clone path = %q, want an error for a layout outside the root", path)
+	}
+	path, err := ClonePath(root, "{owner}-{repo}", "git@github.com:owner/repo.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, "owner-repo"); path != want {
+		t.Errorf("clone path = %q, want %q", path, want)
+	}
+}
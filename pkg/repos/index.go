@@ -0,0 +1,313 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package repos
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"gopkg.in/yaml.v3"
+)
+
+const indexFile = "repos.yaml"
+
+// How long to wait for more repositories before sending what has
+// been found so far. This keeps the table from redrawing for
+// every repository on the first index
+const indexBatch = 100 * time.Millisecond
+
+// Index is the on-disk cache of the repositories found under the
+// configured paths so they can be shown without walking the paths
+//
+// Directories holds the modification time of each directory seen on
+// the last walk that is not a project. Directories which have not
+// been modified are not read again when the index is revalidated
+type Index struct {
+	Repositories []Repository         `yaml:"repositories"`
+	Directories  map[string]time.Time `yaml:"directories,omitempty"`
+	Updated      time.Time            `yaml:"updated"`
+}
+
+// IndexMsg carries the changes found by an indexer
+//
+// Found holds repositories which are new or have changed since they
+// were indexed, including changes to their worktrees. Removed holds
+// the paths of repositories which have gone. Those whose directory
+// no longer exists arrive with the first batch, and any others not
+// found by the walk arrive once the indexer is done
+type IndexMsg struct {
+	Indexer *Indexer
+	Found   []Repository
+	Removed []string
+	Done    bool
+}
+
+// Indexer revalidates the index in the background
+type Indexer struct {
+	changed chan struct{}
+	done    chan struct{}
+	found   []Repository
+	mu      sync.Mutex
+	removed []string
+	result  []Repository
+	err     error
+}
+
+// Load the repositories in the index that belong to the given paths
+// or to the directories listed in the project config
+//
+// A missing or unreadable index gives no repositories
+func LoadIndex(paths []string) []Repository {
+	index, err := readIndex()
+	if err != nil {
+		return nil
+	}
+	paths = scope(paths)
+	return slices.DeleteFunc(index.Repositories, func(r Repository) bool {
+		return !under(r.Path, paths)
+	})
+}
+
+// Revalidate the index for the given paths
+//
+// The paths are walked in the background. Unless force is set,
+// directories which have not been modified since the last walk are
+// not read again and repositories whose git config is unchanged are
+// taken from the index without being opened. As the modification
+// times of the directories are trusted, changes to the project roots
+// need a forced walk to be seen. The index is written once the walk
+// is complete
+func Reindex(paths []string, pattern string, force bool) *Indexer {
+	i := Indexer{
+		changed: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	indexed := LoadIndex(paths)
+	known := make(map[string]Repository, len(indexed))
+	for _, r := range indexed {
+		known[r.Path] = r
+	}
+	var dirs map[string]time.Time
+	if index, err := readIndex(); err == nil && !force {
+		dirs = make(map[string]time.Time)
+		for dir, modified := range index.Directories {
+			if under(dir, paths) {
+				dirs[dir] = modified
+			}
+		}
+	}
+
+	go func() {
+		defer close(i.done)
+		seen := make(map[string]Repository)
+		skip := known
+		if force {
+			skip = nil
+		}
+
+		// a repository whose directory is gone can't be found by the
+		// walk so there is no need to wait for it to finish
+		reported := make(map[string]bool)
+		for path := range known {
+			if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+				reported[path] = true
+			}
+		}
+		if len(reported) > 0 {
+			i.mu.Lock()
+			i.removed = slices.Collect(maps.Keys(reported))
+			i.mu.Unlock()
+			select {
+			case i.changed <- struct{}{}:
+			default:
+			}
+		}
+
+		seenDirs := scan(paths, pattern, skip, dirs, func(repo Repository) {
+			i.mu.Lock()
+			defer i.mu.Unlock()
+			if _, ok := seen[repo.Path]; ok {
+				return
+			}
+			seen[repo.Path] = repo
+			if previous, ok := known[repo.Path]; ok && previous.same(repo) {
+				return
+			}
+			i.found = append(i.found, repo)
+			select {
+			case i.changed <- struct{}{}:
+			default:
+			}
+		})
+
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		for path := range known {
+			if _, ok := seen[path]; !ok && !reported[path] {
+				i.removed = append(i.removed, path)
+			}
+		}
+		i.result = make([]Repository, 0, len(seen))
+		for _, repo := range seen {
+			i.result = append(i.result, repo)
+		}
+		i.result = unique(i.result)
+		i.err = saveIndex(paths, i.result, seenDirs)
+	}()
+	return &i
+}
+
+// Next returns a command that waits for the next set of changes
+//
+// Once the indexer is done the final message has Done set and
+// Next should not be called again
+func (i *Indexer) Next() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case <-i.done:
+		case <-i.changed:
+			select {
+			case <-i.done:
+			case <-time.After(indexBatch):
+			}
+		}
+
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		msg := IndexMsg{
+			Indexer: i,
+			Found:   i.found,
+			Removed: i.removed,
+		}
+		i.found = nil
+		i.removed = nil
+		select {
+		case <-i.done:
+			msg.Done = true
+		default:
+		}
+		return msg
+	}
+}
+
+// Wait for the indexer to finish and return all repositories
+func (i *Indexer) Wait() ([]Repository, error) {
+	<-i.done
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.result, i.err
+}
+
+func indexPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, helpers.ExecutableName(), indexFile)
+}
+
+// Read the index from the cache
+//
+// An index which cannot be parsed is empty so it is rebuilt
+func readIndex() (Index, error) {
+	var index Index
+	content, err := os.ReadFile(indexPath())
+	if err != nil {
+		return index, err
+	}
+	if err := yaml.Unmarshal(content, &index); err != nil {
+		return Index{}, nil
+	}
+	return index, nil
+}
+
+// Write the index, keeping any repositories and directories from
+// paths that were not part of this walk
+func saveIndex(paths []string, repositories []Repository, dirs map[string]time.Time) error {
+	filename := indexPath()
+	index, err := readIndex()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	paths = scope(paths)
+	index.Repositories = slices.DeleteFunc(index.Repositories, func(r Repository) bool {
+		return under(r.Path, paths)
+	})
+	index.Repositories = unique(append(index.Repositories, repositories...))
+	maps.DeleteFunc(index.Directories, func(dir string, _ time.Time) bool {
+		return under(dir, paths)
+	})
+	if index.Directories == nil {
+		index.Directories = make(map[string]time.Time, len(dirs))
+	}
+	maps.Copy(index.Directories, dirs)
+	index.Updated = time.Now()
+
+	content, err := yaml.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+indexFile+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// Has anything shown in the table changed since the repository was indexed
+func (r Repository) same(other Repository) bool {
+	return r.Name == other.Name && r.Owner == other.Owner && r.Url == other.Url &&
+		r.Kind == other.Kind && slices.Equal(r.Worktrees, other.Worktrees)
+}
+
+// Is the path inside any of the roots
+func under(path string, roots []string) bool {
+	for _, root := range roots {
+		root = filepath.Clean(root)
+		if path == root || strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
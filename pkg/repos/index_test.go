@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package repos
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReindexReportsRemovedRepositoriesOnce(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	for _, name := range []string{"api", "web"} {
+		runGit(t, root, "init", "-q", name)
+	}
+	if _, err := Reindex([]string{root}, DefaultPattern, true).Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	removed := filepath.Join(root, "web")
+	if err := os.RemoveAll(removed); err != nil {
+		t.Fatal(err)
+	}
+	indexer := Reindex([]string{root}, DefaultPattern, true)
+	reported := make([]string, 0)
+	for {
+		msg := indexer.Next()().(IndexMsg)
+		reported = append(reported, msg.Removed...)
+		if msg.Done {
+			break
+		}
+	}
+	if !slices.Equal(reported, []string{removed}) {
+		t.Errorf("removed = %v, want only %q", reported, removed)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package repos
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mproffitt/bmx/pkg/tmux"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+)
+
+const (
+	// how many commits are shown in the preview
+	previewCommits = 10
+
+	// longer READMEs are cut short
+	maxReadme = 64 * 1024
+)
+
+// The names a README is looked for under, in order
+var readmes = []string{"README.md", "README", "README.markdown", "README.txt", "readme.md"}
+
+// Preview is what is shown about a repository before
+// a session is opened for it
+type Preview struct {
+	Path     string
+	Readme   string
+	Commits  []Commit
+	Status   Status
+	Sessions []string
+}
+
+// Commit is a single entry in the preview's commit log
+type Commit struct {
+	Hash    string
+	Subject string
+	Author  string
+	When    time.Time
+}
+
+// PreviewMsg carries a preview read by PreviewCmd
+type PreviewMsg struct {
+	Preview Preview
+}
+
+// PreviewCmd reads the preview of the repository at path
+// in the background
+//
+// Anything that cannot be read is left empty so projects
+// without git or a README still show what they have
+func PreviewCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		return PreviewMsg{Preview: ReadPreview(path)}
+	}
+}
+
+// Read the README, recent commits, status and open sessions
+// of the repository at path
+func ReadPreview(path string) Preview {
+	preview := Preview{
+		Path:     path,
+		Readme:   readme(path),
+		Sessions: tmux.SessionsAt(path),
+	}
+	preview.Status, _ = CachedStatus(path)
+	preview.Commits, _ = commits(path, previewCommits)
+	return preview
+}
+
+func readme(path string) string {
+	for _, name := range readmes {
+		content, err := os.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			continue
+		}
+		if len(content) > maxReadme {
+			content = content[:maxReadme]
+		}
+		return string(content)
+	}
+	return ""
+}
+
+// Read the most recent commits reachable from HEAD
+func commits(path string, count int) ([]Commit, error) {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	iter, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	commits := make([]Commit, 0, count)
+	err = iter.ForEach(func(c *object.Commit) error {
+		if len(commits) == count {
+			return storer.ErrStop
+		}
+		subject, _, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		commits = append(commits, Commit{
+			Hash:    c.Hash.String()[:7],
+			Subject: subject,
+			Author:  c.Author.Name,
+			When:    c.Author.When,
+		})
+		return nil
+	})
+	return commits, err
+}
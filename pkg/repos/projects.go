@@ -0,0 +1,189 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package repos
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/helpers"
+)
+
+// Kind of project found for a directory that is not a git repository
+// and has no marker file. Git repositories have no kind
+const KindDirectory = "directory"
+
+// the project options read from the config
+var projects = struct {
+	directories []string
+	markers     []string
+	roots       map[string]root
+}{roots: make(map[string]root)}
+
+// Options for walking one of the configured paths
+type root struct {
+	maxDepth int
+	include  []*regexp.Regexp
+	exclude  []*regexp.Regexp
+}
+
+// SetProjects sets how projects are found under the configured paths
+//
+// A leading `~/` in any directory or root is expanded to the users
+// home directory. An error is returned for any glob that is not valid
+func SetProjects(p config.Projects) error {
+	projects.markers = slices.Clone(p.Markers)
+	projects.directories = make([]string, 0, len(p.Directories))
+	for _, dir := range p.Directories {
+		projects.directories = append(projects.directories,
+			filepath.Clean(helpers.ExpandHome(dir)))
+	}
+
+	projects.roots = make(map[string]root, len(p.Roots))
+	for _, r := range p.Roots {
+		var (
+			options = root{maxDepth: r.MaxDepth}
+			err     error
+		)
+		if options.include, err = compileGlobs(r.Include); err != nil {
+			return err
+		}
+		if options.exclude, err = compileGlobs(r.Exclude); err != nil {
+			return err
+		}
+		projects.roots[filepath.Clean(helpers.ExpandHome(r.Path))] = options
+	}
+	return nil
+}
+
+// The paths the index holds repositories for
+//
+// This is the configured paths and any directories listed explicitly
+func scope(paths []string) []string {
+	return append(slices.Clone(paths), projects.directories...)
+}
+
+func compileGlobs(globs []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		re, err := compileGlob(glob)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// Turn a glob into a regular expression matched against a slash
+// separated relative path
+//
+// A glob without a slash matches the last element of the path
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	glob = strings.Trim(filepath.ToSlash(glob), "/")
+	prefix := "^"
+	if !strings.Contains(glob, "/") {
+		prefix = "^(.*/)?"
+	}
+
+	var expr strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("missing ] in %q", glob)
+			}
+			class := glob[i+1 : i+end]
+			if negated, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + negated
+			}
+			expr.WriteString("[" + class + "]")
+			i += end
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return regexp.Compile(prefix + expr.String() + "$")
+}
+
+func matchAny(globs []*regexp.Regexp, path string) bool {
+	for _, glob := range globs {
+		if glob.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Find the project at path, if any
+//
+// Git repositories are found by pattern, anything else by the
+// configured marker files. Repositories in known whose git config
+// has not been modified since they were last read are not opened
+func detect(path, pattern string, known map[string]Repository) (Repository, bool) {
+	if info, err := os.Stat(filepath.Join(path, pattern)); err == nil {
+		if repo, ok := known[path]; ok && repo.Kind == "" && repo.Modified.Equal(info.ModTime()) {
+			repo.Worktrees = worktrees(path)
+			return repo, true
+		}
+		repo, err := open(path)
+		if err != nil {
+			return Repository{}, false
+		}
+		repo.Modified = info.ModTime()
+		repo.Worktrees = worktrees(path)
+		return repo, true
+	}
+
+	for _, marker := range projects.markers {
+		if info, err := os.Stat(filepath.Join(path, marker)); err == nil {
+			repo := directory(path, marker)
+			repo.Modified = info.ModTime()
+			return repo, true
+		}
+	}
+	return Repository{}, false
+}
+
+// A project named after its directory and the one holding it
+func directory(path, kind string) Repository {
+	return Repository{
+		Name:  strings.ToLower(filepath.Base(path)),
+		Owner: strings.ToLower(filepath.Base(filepath.Dir(path))),
+		Path:  path,
+		Kind:  kind,
+	}
+}
@@ -20,88 +20,303 @@
 package repos
 
 import (
-	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/charlievieth/fastwalk"
 	tea "github.com/charmbracelet/bubbletea"
-	giturl "github.com/kubescape/go-git-url"
+	"github.com/charmbracelet/log"
+	"github.com/mproffitt/bmx/pkg/frecency"
 	"github.com/mproffitt/bmx/pkg/helpers"
 	"github.com/mproffitt/bmx/pkg/tmux"
 	git "gopkg.in/src-d/go-git.v4"
 )
 
+// DefaultPattern is the file that marks a directory as a repository
+const DefaultPattern = ".git/config"
+
+// Repository is a project found under one of the configured paths
+//
+// Modified is the modification time of the repositories git config
+// and is used to tell if the repository needs to be read again.
+// Worktrees are read on every walk as adding one does not change
+// the config.
+//
+// Kind is empty for git repositories. Other projects have the marker
+// file they were found by or `directory` when listed explicitly, and
+// Modified is then the time the marker was changed
 type Repository struct {
-	Name  string
-	Owner string
-	Path  string
-	Url   string
+	Name      string     `yaml:"name"`
+	Owner     string     `yaml:"owner"`
+	Path      string     `yaml:"path"`
+	Url       string     `yaml:"url"`
+	Kind      string     `yaml:"kind,omitempty"`
+	Modified  time.Time  `yaml:"modified"`
+	Worktrees []Worktree `yaml:"worktrees,omitempty"`
 }
 
+// Find all repositories under the given paths
+//
+// This always walks the paths, use the index to avoid reading
+// repositories that have not changed
 func Find(paths []string, pattern string) ([]Repository, error) {
+	var (
+		mu       sync.Mutex
+		repoList []Repository
+	)
+	scan(paths, pattern, nil, nil, func(repo Repository) {
+		mu.Lock()
+		repoList = append(repoList, repo)
+		mu.Unlock()
+	})
+	return unique(repoList), nil
+}
+
+// Walk the paths calling found for every project
+//
+// Projects in known whose git config or marker has not been modified
+// since they were last read are passed on without being opened. The
+// walk does not go inside a project. found may be called from many
+// goroutines at once.
+//
+// dirs holds the modification times of the directories seen on the
+// last walk. A directory that has not been modified since then holds
+// the same entries, so it is not read again and only the directories
+// and projects known to be inside it are checked. The modification
+// times of the directories seen on this walk are returned
+func scan(paths []string, pattern string, known map[string]Repository, dirs map[string]time.Time, found func(Repository)) map[string]time.Time {
+	w := newWalker(pattern, known, dirs, found)
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		options := projects.roots[path]
+		if _, ok := dirs[path]; ok {
+			w.revisit(path, options, path)
+			continue
+		}
+		w.walk(path, options, path)
+	}
+
+	// listed directories are always projects
+	for _, dir := range projects.directories {
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		repo, ok := detect(dir, pattern, known)
+		if !ok {
+			repo = directory(dir, KindDirectory)
+		}
+		found(repo)
+	}
+	return w.seen
+}
+
+// walker tracks the directories seen while walking the paths
+type walker struct {
+	pattern  string
+	known    map[string]Repository
+	dirs     map[string]time.Time
+	children map[string][]string
+	found    func(Repository)
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func newWalker(pattern string, known map[string]Repository, dirs map[string]time.Time, found func(Repository)) *walker {
+	w := walker{
+		pattern:  pattern,
+		known:    known,
+		dirs:     dirs,
+		children: make(map[string][]string),
+		found:    found,
+		seen:     make(map[string]time.Time),
+	}
+	for dir := range dirs {
+		w.children[filepath.Dir(dir)] = append(w.children[filepath.Dir(dir)], dir)
+	}
+	for dir := range known {
+		w.children[filepath.Dir(dir)] = append(w.children[filepath.Dir(dir)], dir)
+	}
+	return &w
+}
+
+// Walk everything under start
+func (w *walker) walk(path string, options root, start string) {
 	conf := fastwalk.Config{
 		Follow: true,
 	}
 
-	repositories := make(chan Repository)
-	walkFn := func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
+	walkFn := func(dir string, d fs.DirEntry, err error) error {
+		if err != nil || !(d.IsDir() || d.Type()&fs.ModeSymlink != 0) {
 			return nil
 		}
-
-		if _, err := os.Stat(filepath.Join(path, pattern)); os.IsNotExist(err) {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
 			return nil
 		}
-
-		if _, err := os.Stat(filepath.Join(path, "..", ".git")); err == nil {
+		if !w.check(path, options, dir, info.ModTime(), false) {
 			return fastwalk.SkipDir
 		}
+		return nil
+	}
 
-		repo, err := git.PlainOpen(path)
-		if err != nil {
-			return nil
-		}
+	if err := fastwalk.Walk(&conf, start, walkFn); err != nil {
+		log.Debug("failed to walk", "path", start, "error", err)
+	}
+}
 
-		repoUrl, err := repo.Remote("origin")
-		if err != nil {
-			return nil
-		}
+// Walk a directory seen on the last walk
+//
+// Anything inside a modified directory that was not seen on the last
+// walk is walked in full
+func (w *walker) revisit(path string, options root, dir string) {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return
+	}
+	modified, ok := w.dirs[dir]
+	unchanged := ok && modified.Equal(info.ModTime())
+	if !w.check(path, options, dir, info.ModTime(), unchanged) {
+		return
+	}
 
-		url := repoUrl.Config().URLs[0]
-		gitURL, err := giturl.NewGitURL(url)
-		if err != nil {
-			// skip unknown repo types
-			return nil
+	children := w.children[dir]
+	if !unchanged {
+		children = subdirectories(dir)
+	}
+	for _, child := range children {
+		_, seen := w.dirs[child]
+		if _, ok := w.known[child]; ok || seen {
+			w.revisit(path, options, child)
+			continue
 		}
+		w.walk(path, options, child)
+	}
+}
+
+// Check a directory, returning true if the walk should go inside it
+//
+// A directory which is unchanged was not a project on the last walk
+// and cannot have become one without being modified
+func (w *walker) check(path string, options root, dir string, modified time.Time, unchanged bool) bool {
+	rel, _ := filepath.Rel(path, dir)
+	rel = filepath.ToSlash(rel)
+	if rel != "." && matchAny(options.exclude, rel) {
+		return false
+	}
 
-		repositories <- Repository{
-			Name:  strings.ToLower(gitURL.GetRepoName()),
-			Owner: strings.ToLower(gitURL.GetOwnerName()),
-			Path:  path,
-			Url:   url,
+	if !unchanged {
+		if repo, ok := detect(dir, w.pattern, w.known); ok {
+			if len(options.include) == 0 || matchAny(options.include, rel) {
+				w.found(repo)
+			}
+			return false
 		}
-		return err
 	}
 
-	var repoList []Repository
-	go func() {
-		for _, root := range paths {
-			if err := fastwalk.Walk(&conf, root, walkFn); err != nil {
-				fmt.Fprintf(os.Stderr, "%s: %v\n", root, err)
+	w.mu.Lock()
+	w.seen[dir] = modified
+	w.mu.Unlock()
+
+	if options.maxDepth > 0 && rel != "." &&
+		strings.Count(rel, "/")+1 >= options.maxDepth {
+		return false
+	}
+	return true
+}
+
+// List the directories inside dir
+//
+// Links to a directory holding dir are skipped so the walk
+// cannot loop
+func subdirectories(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil
+	}
+
+	dirs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.IsDir():
+		case entry.Type()&fs.ModeSymlink != 0:
+			target, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				continue
 			}
+			if info, err := os.Stat(target); err != nil || !info.IsDir() ||
+				resolved == target || strings.HasPrefix(resolved, target+string(filepath.Separator)) {
+				continue
+			}
+		default:
+			continue
 		}
-		close(repositories)
-	}()
+		dirs = append(dirs, path)
+	}
+	return dirs
+}
 
-	for repo := range repositories {
-		repoList = append(repoList, repo)
+// Read a repository from its remote
+//
+// The `origin` remote is used if there is one, otherwise the first
+// remote by name. Repositories without a remote, or whose remote
+// cannot be parsed, are named after their directory
+func open(path string) (Repository, error) {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return Repository{}, err
 	}
 
-	return unique(repoList), nil
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return Repository{}, err
+	}
+	slices.SortFunc(remotes, func(a, b *git.Remote) int {
+		switch {
+		case a.Config().Name == "origin":
+			return -1
+		case b.Config().Name == "origin":
+			return 1
+		}
+		return strings.Compare(a.Config().Name, b.Config().Name)
+	})
+
+	project := directory(path, "")
+	for _, remote := range remotes {
+		if len(remote.Config().URLs) == 0 {
+			continue
+		}
+		project.Url = remote.Config().URLs[0]
+		if _, owner, name, err := ParseURL(project.Url); err == nil {
+			project.Name = strings.ToLower(name)
+			project.Owner = strings.ToLower(owner)
+		}
+		break
+	}
+	return project, nil
+}
+
+// Read the repository at path
+//
+// Directories without git are named after the directory and the
+// one holding it
+func ReadRepository(path string) Repository {
+	repo, err := open(path)
+	if err != nil {
+		return directory(path, KindDirectory)
+	}
+	return repo
 }
 
 func RepoCallback(data map[string]any, useKubeConfig bool) tea.Cmd {
@@ -123,6 +338,7 @@ func RepoCallback(data map[string]any, useKubeConfig bool) tea.Cmd {
 		if err := tmux.NewSessionOrAttach(data, useKubeConfig); err != nil {
 			return helpers.NewErrorCmd(err)
 		}
+		_ = frecency.Visit(frecency.Repositories, data["path"].(string))
 		return tea.Quit()
 	}
 }
@@ -133,10 +349,8 @@ func unique(sample []Repository) []Repository {
 		return sample[i].Path < sample[j].Path
 	})
 	for _, v := range sample {
-		for _, u := range unique {
-			if v.Path == u.Path {
-				continue
-			}
+		if len(unique) > 0 && unique[len(unique)-1].Path == v.Path {
+			continue
 		}
 		unique = append(unique, v)
 	}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package repos
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mproffitt/bmx/pkg/config"
+	bmx "github.com/mproffitt/bmx/pkg/exec"
+	"github.com/mproffitt/bmx/pkg/frecency"
+	"github.com/mproffitt/bmx/pkg/helpers"
+)
+
+// Where a directory shown alongside the indexed repositories
+// was found. Sources earlier in the list take precedence when the
+// same directory is found more than once
+const (
+	SourceBookmark = "bookmark"
+	SourceRecent   = "recent"
+	SourceZoxide   = "zoxide"
+)
+
+// Only the highest scoring directories known to zoxide are used
+const maxZoxide = 50
+
+// Directory is a repository or plain directory found by one of the
+// sources rather than by walking the configured paths
+//
+// Score is how often and how recently the directory has been used.
+// Each source is scaled to between 0 and 1 relative to its highest
+// scoring directory so directories from different sources can be
+// ranked together
+type Directory struct {
+	Repository
+	Source string
+	Score  float64
+}
+
+// SourcesMsg carries the directories found by the extra sources
+type SourcesMsg struct {
+	Directories []Directory
+}
+
+// Read the extra directory sources in the background
+func SourcesCmd(c *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		return SourcesMsg{Directories: ReadSources(c)}
+	}
+}
+
+// ReadSources finds the bookmarked directories, the paths of saved
+// and recently opened sessions and the directories known to zoxide
+// when it is installed
+//
+// Directories which no longer exist are skipped. Git repositories are
+// named after their remote, anything else after its directory
+func ReadSources(c *config.Config) []Directory {
+	var (
+		found = make([]Directory, 0)
+		seen  = make(map[string]bool)
+	)
+	add := func(path, source string, score float64) {
+		path = filepath.Clean(helpers.ExpandHome(path))
+		if seen[path] || !isDir(path) {
+			return
+		}
+		seen[path] = true
+		found = append(found, Directory{
+			Repository: ReadRepository(path),
+			Source:     source,
+			Score:      score,
+		})
+	}
+
+	for _, path := range c.Bookmarks {
+		add(path, SourceBookmark, 0)
+	}
+
+	sessions := frecency.Normalise(frecency.Scores(frecency.Sessions))
+	for _, session := range c.Sessions {
+		add(session.Path, SourceRecent, sessions[session.Name])
+	}
+	for path, score := range frecency.Normalise(frecency.Scores(frecency.Repositories)) {
+		add(path, SourceRecent, score)
+	}
+
+	for _, z := range zoxide() {
+		add(z.Path, SourceZoxide, z.Score)
+	}
+	return found
+}
+
+// Query zoxide for its highest scoring directories
+//
+// Scores are scaled to between 0 and 1 relative to the highest
+// scoring directory. Nothing is returned if zoxide is not installed or fails
+func zoxide() []Directory {
+	z, err := exec.LookPath("zoxide")
+	if err != nil {
+		return nil
+	}
+	stdout, _, err := bmx.Exec(z, []string{"query", "--list", "--score"})
+	if err != nil {
+		return nil
+	}
+
+	// each line is the score followed by the path
+	directories := make([]Directory, 0)
+	for _, line := range strings.Split(stdout, "\n") {
+		score, path, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(score, 64)
+		if err != nil {
+			continue
+		}
+		directories = append(directories, Directory{
+			Repository: Repository{Path: strings.TrimSpace(path)},
+			Score:      value,
+		})
+	}
+	slices.SortStableFunc(directories, func(a, b Directory) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	directories = directories[:min(len(directories), maxZoxide)]
+	if len(directories) > 0 && directories[0].Score > 0 {
+		highest := directories[0].Score
+		for i := range directories {
+			directories[i].Score /= highest
+		}
+	}
+	return directories
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
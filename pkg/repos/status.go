@@ -0,0 +1,309 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package repos
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const (
+	// how long a status is trusted before the repository is read again
+	statusTTL = time.Minute
+
+	// stop counting ahead and behind after this many commits
+	maxDistance = 1000
+
+	// how many repositories are read at once
+	statusWorkers = 8
+)
+
+// Status is the state of a repositories working tree
+//
+// Upstream is false when the branch does not track a remote branch
+// in which case Ahead and Behind are always zero
+type Status struct {
+	Branch     string
+	Detached   bool
+	Dirty      bool
+	Upstream   bool
+	Ahead      int
+	Behind     int
+	Stashes    int
+	LastCommit time.Time
+	Checked    time.Time
+}
+
+// StatusMsg carries the statuses read by StatusCmd, keyed by path
+//
+// Repositories that could not be read are missing from the map
+type StatusMsg struct {
+	Statuses map[string]Status
+}
+
+var workers = make(chan struct{}, statusWorkers)
+
+var statuses = struct {
+	sync.Mutex
+	cache map[string]Status
+}{cache: make(map[string]Status)}
+
+// StatusCmd reads the status of each path in the background
+func StatusCmd(paths []string) tea.Cmd {
+	return func() tea.Msg {
+		var (
+			mu  sync.Mutex
+			wg  sync.WaitGroup
+			msg = StatusMsg{Statuses: make(map[string]Status, len(paths))}
+		)
+		for _, path := range paths {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				workers <- struct{}{}
+				defer func() { <-workers }()
+
+				status, err := CachedStatus(path)
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				msg.Statuses[path] = status
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		return msg
+	}
+}
+
+// CachedStatus returns the status of the repository at path, reading it
+// again if it has not been read in the last minute
+func CachedStatus(path string) (Status, error) {
+	statuses.Lock()
+	status, ok := statuses.cache[path]
+	statuses.Unlock()
+	if ok && time.Since(status.Checked) < statusTTL {
+		return status, nil
+	}
+
+	status, err := ReadStatus(path)
+	if err != nil {
+		return status, err
+	}
+	statuses.Lock()
+	statuses.cache[path] = status
+	statuses.Unlock()
+	return status, nil
+}
+
+// ReadStatus reads the branch, working tree and upstream state of the
+// repository at path
+func ReadStatus(path string) (Status, error) {
+	status := Status{
+		Checked: time.Now(),
+		Stashes: countStashes(path),
+	}
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return status, err
+	}
+
+	head, err := repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		// a new repository without any commits
+		status.Branch = unbornBranch(repo)
+		status.Dirty, err = isDirty(repo)
+		return status, err
+	}
+	if err != nil {
+		return status, err
+	}
+
+	status.Branch = head.Name().Short()
+	if !head.Name().IsBranch() {
+		status.Branch = head.Hash().String()[:7]
+		status.Detached = true
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return status, err
+	}
+	status.LastCommit = commit.Committer.When
+
+	if status.Dirty, err = isDirty(repo); err != nil {
+		return status, err
+	}
+
+	if !status.Detached {
+		if upstream := upstreamHash(repo, head.Name().Short()); !upstream.IsZero() {
+			status.Upstream = true
+			status.Ahead, status.Behind = distance(repo, head.Hash(), upstream)
+		}
+	}
+	return status, nil
+}
+
+func isDirty(repo *git.Repository) (bool, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return false, err
+	}
+	s, err := worktree.Status()
+	if err != nil {
+		return false, err
+	}
+	return !s.IsClean(), nil
+}
+
+// The branch HEAD points at before the first commit is made
+func unbornBranch(repo *git.Repository) string {
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return ""
+	}
+	return head.Target().Short()
+}
+
+// Find the commit the branch tracks from its remote, if any
+func upstreamHash(repo *git.Repository, branch string) plumbing.Hash {
+	cfg, err := repo.Config()
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	b, ok := cfg.Branches[branch]
+	if !ok || b.Remote == "" || b.Merge == "" {
+		return plumbing.ZeroHash
+	}
+	name := plumbing.NewRemoteReferenceName(b.Remote, b.Merge.Short())
+	if b.Remote == "." {
+		name = b.Merge
+	}
+	ref, err := repo.Reference(name, true)
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	return ref.Hash()
+}
+
+// Count the commits reachable from local but not upstream and
+// from upstream but not local
+//
+// Both histories are walked newest first, as git does, marking which
+// side each commit is reachable from until everything left to walk is
+// reachable from both and older than anything walked from one side
+// only. Commits made in the same second could otherwise stop the walk
+// before a shared commit is reached from both sides. Walking stops
+// after twice maxDistance commits so very long histories are not read
+// in full, and the counts are capped at maxDistance
+func distance(repo *git.Repository, local, upstream plumbing.Hash) (ahead, behind int) {
+	if local == upstream {
+		return 0, 0
+	}
+
+	const (
+		ours = 1 << iota
+		theirs
+		both = ours | theirs
+	)
+	var (
+		flags = make(map[plumbing.Hash]int)
+		queue = make([]*object.Commit, 0)
+	)
+	push := func(hash plumbing.Hash, flag int) {
+		if flags[hash]&flag == flag {
+			return
+		}
+		flags[hash] |= flag
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return
+		}
+		// newest first
+		i, _ := slices.BinarySearchFunc(queue, commit, func(a, b *object.Commit) int {
+			return b.Committer.When.Compare(a.Committer.When)
+		})
+		queue = slices.Insert(queue, i, commit)
+	}
+	// the oldest commit walked while reachable from one side only
+	var oldest time.Time
+	common := func() bool {
+		if !oldest.IsZero() && !queue[0].Committer.When.Before(oldest) {
+			return false
+		}
+		return !slices.ContainsFunc(queue, func(c *object.Commit) bool {
+			return flags[c.Hash] != both
+		})
+	}
+
+	push(local, ours)
+	push(upstream, theirs)
+	for visited := 0; len(queue) > 0 && visited < 2*maxDistance && !common(); visited++ {
+		commit := queue[0]
+		queue = queue[1:]
+		if flags[commit.Hash] != both && (oldest.IsZero() || commit.Committer.When.Before(oldest)) {
+			oldest = commit.Committer.When
+		}
+		for _, parent := range commit.ParentHashes {
+			push(parent, flags[commit.Hash])
+		}
+	}
+
+	for _, flag := range flags {
+		switch flag {
+		case ours:
+			ahead++
+		case theirs:
+			behind++
+		}
+	}
+	return min(ahead, maxDistance), min(behind, maxDistance)
+}
+
+// go-git does not read reflogs so stashes are counted
+// straight from the stash log
+//
+// Stashes are shared by every worktree of a repository so the log
+// is read from the common git directory
+func countStashes(path string) int {
+	f, err := os.Open(filepath.Join(commonDir(path), "logs", "refs", "stash"))
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		count++
+	}
+	return count
+}
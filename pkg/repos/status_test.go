@@ -0,0 +1,278 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package repos
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Create a git repository in a temporary directory with a single
+// commit on main
+//
+// Tests using it are skipped if git is not installed
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, v := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(v+"_NAME", "bmx")
+		t.Setenv(v+"_EMAIL", "bmx@example.com")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	writeFile(t, filepath.Join(dir, "README.md"), "readme\n")
+	runGit(t, dir, "add", "README.md")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+// Run git in dir and return its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, filename, content string) {
+	t.Helper()
+	if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Add empty commits to the current branch
+//
+// fast-import is used so long histories are quick to build
+func commitMany(t *testing.T, dir string, count int) {
+	t.Helper()
+	var stream strings.Builder
+	fmt.Fprintf(&stream, "reset refs/heads/main\nfrom %s\n\n", runGit(t, dir, "rev-parse", "HEAD"))
+	for i := range count {
+		message := fmt.Sprintf("commit %d", i)
+		fmt.Fprintf(&stream, "commit refs/heads/main\ncommitter bmx <bmx@example.com> %d +0000\ndata %d\n%s\n\n",
+			1700000000+i, len(message), message)
+	}
+
+	cmd := exec.Command("git", "fast-import", "--quiet")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(stream.String())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git fast-import: %v\n%s", err, out)
+	}
+	runGit(t, dir, "reset", "-q", "--hard", "main")
+}
+
+// Make origin/main the upstream of main, pointing at the given commit
+func setUpstream(t *testing.T, dir, commit string) {
+	t.Helper()
+	runGit(t, dir, "update-ref", "refs/remotes/origin/main", commit)
+	runGit(t, dir, "config", "branch.main.remote", "origin")
+	runGit(t, dir, "config", "branch.main.merge", "refs/heads/main")
+}
+
+func TestReadStatusAheadAndBehind(t *testing.T) {
+	dir := newTestRepo(t)
+	base := runGit(t, dir, "rev-parse", "HEAD")
+
+	// the upstream has moved on by three commits
+	runGit(t, dir, "checkout", "-q", "-b", "upstream")
+	for i := range 3 {
+		runGit(t, dir, "commit", "-q", "--allow-empty", "-m", fmt.Sprintf("theirs %d", i))
+	}
+	setUpstream(t, dir, runGit(t, dir, "rev-parse", "HEAD"))
+
+	// and main by two
+	runGit(t, dir, "checkout", "-q", "main")
+	for i := range 2 {
+		runGit(t, dir, "commit", "-q", "--allow-empty", "-m", fmt.Sprintf("ours %d", i))
+	}
+	if runGit(t, dir, "merge-base", "main", "origin/main") != base {
+		t.Fatal("branches do not diverge from the first commit")
+	}
+
+	status, err := ReadStatus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Branch != "main" || status.Detached || status.Dirty {
+		t.Errorf("status = %+v, want a clean main branch", status)
+	}
+	if !status.Upstream || status.Ahead != 2 || status.Behind != 3 {
+		t.Errorf("upstream %t ahead %d behind %d, want true 2 3", status.Upstream, status.Ahead, status.Behind)
+	}
+	if status.LastCommit.IsZero() {
+		t.Error("last commit time was not read")
+	}
+
+	// merging the upstream leaves only the local commits and the merge
+	runGit(t, dir, "merge", "-q", "--no-edit", "origin/main")
+	if status, err = ReadStatus(dir); err != nil {
+		t.Fatal(err)
+	}
+	if status.Ahead != 3 || status.Behind != 0 {
+		t.Errorf("after merge ahead %d behind %d, want 3 0", status.Ahead, status.Behind)
+	}
+
+	writeFile(t, filepath.Join(dir, "new.txt"), "untracked\n")
+	if status, err = ReadStatus(dir); err != nil || !status.Dirty {
+		t.Errorf("dirty = %t (error %v), want an untracked file to make it dirty", status.Dirty, err)
+	}
+}
+
+func TestReadStatusCapsDistance(t *testing.T) {
+	dir := newTestRepo(t)
+	setUpstream(t, dir, runGit(t, dir, "rev-parse", "HEAD"))
+	commitMany(t, dir, maxDistance+5)
+
+	status, err := ReadStatus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Ahead != maxDistance || status.Behind != 0 {
+		t.Errorf("ahead %d behind %d, want %d 0", status.Ahead, status.Behind, maxDistance)
+	}
+
+	// and the same the other way round
+	setUpstream(t, dir, "main")
+	runGit(t, dir, "reset", "-q", "--hard", "main~"+fmt.Sprint(maxDistance+5))
+	if status, err = ReadStatus(dir); err != nil {
+		t.Fatal(err)
+	}
+	if status.Ahead != 0 || status.Behind != maxDistance {
+		t.Errorf("ahead %d behind %d, want 0 %d", status.Ahead, status.Behind, maxDistance)
+	}
+}
+
+func TestReadStatusWithoutUpstream(t *testing.T) {
+	dir := newTestRepo(t)
+	status, err := ReadStatus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Upstream || status.Ahead != 0 || status.Behind != 0 {
+		t.Errorf("status = %+v, want no upstream", status)
+	}
+
+	head := runGit(t, dir, "rev-parse", "HEAD")
+	runGit(t, dir, "checkout", "-q", "--detach")
+	if status, err = ReadStatus(dir); err != nil {
+		t.Fatal(err)
+	}
+	if !status.Detached || status.Branch != head[:7] {
+		t.Errorf("detached %t branch %q, want true %q", status.Detached, status.Branch, head[:7])
+	}
+}
+
+func TestReadStatusUnbornBranch(t *testing.T) {
+	dir := newTestRepo(t)
+	runGit(t, dir, "checkout", "-q", "--orphan", "fresh")
+
+	status, err := ReadStatus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Branch != "fresh" || !status.LastCommit.IsZero() {
+		t.Errorf("status = %+v, want the unborn branch fresh", status)
+	}
+}
+
+func TestCountStashes(t *testing.T) {
+	dir := newTestRepo(t)
+	if count := countStashes(dir); count != 0 {
+		t.Errorf("stashes = %d, want 0", count)
+	}
+
+	for i := range 2 {
+		writeFile(t, filepath.Join(dir, "README.md"), fmt.Sprintf("change %d\n", i))
+		runGit(t, dir, "stash", "-q")
+	}
+	if count := countStashes(dir); count != 2 {
+		t.Errorf("stashes = %d, want 2", count)
+	}
+
+	// worktrees share the stashes of their repository
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	runGit(t, dir, "worktree", "add", "-q", "-b", "other", worktree)
+	if count := countStashes(worktree); count != 2 {
+		t.Errorf("stashes in worktree = %d, want 2", count)
+	}
+
+	runGit(t, dir, "stash", "drop", "-q")
+	status, err := ReadStatus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Stashes != 1 || status.Dirty {
+		t.Errorf("stashes %d dirty %t, want 1 false", status.Stashes, status.Dirty)
+	}
+}
+
+func TestCachedStatusExpires(t *testing.T) {
+	dir := newTestRepo(t)
+	t.Cleanup(func() {
+		statuses.Lock()
+		delete(statuses.cache, dir)
+		statuses.Unlock()
+	})
+
+	first, err := CachedStatus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// changes are not seen until the cached status expires
+	writeFile(t, filepath.Join(dir, "new.txt"), "untracked\n")
+	cached, err := CachedStatus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached.Dirty || !cached.Checked.Equal(first.Checked) {
+		t.Errorf("status = %+v, want the cached status", cached)
+	}
+
+	statuses.Lock()
+	entry := statuses.cache[dir]
+	entry.Checked = time.Now().Add(-statusTTL)
+	statuses.cache[dir] = entry
+	statuses.Unlock()
+
+	status, err := CachedStatus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Dirty || !status.Checked.After(first.Checked) {
+		t.Errorf("status = %+v, want it read again once expired", status)
+	}
+}
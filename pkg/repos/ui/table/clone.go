@@ -0,0 +1,114 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package table
+
+import (
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mproffitt/bmx/pkg/components/createpanel"
+	"github.com/mproffitt/bmx/pkg/components/dialog"
+	"github.com/mproffitt/bmx/pkg/components/icons"
+	"github.com/mproffitt/bmx/pkg/components/overlay"
+	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/repos"
+)
+
+const cloneWidth = 60
+
+// Where a remote entered as the session name will be cloned to
+//
+// Repositories are cloned under the first configured path
+// using the clone layout from the config
+func (m *Model) clonePath(remote string) string {
+	root, _ := os.UserHomeDir()
+	if len(m.paths) > 0 {
+		root = m.paths[0]
+	}
+	path, err := repos.ClonePath(root, m.config.CloneLayout, remote)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// Clone the remote and open a session in it once done
+func (m *Model) clone(msg createpanel.ObserverMsg) tea.Cmd {
+	_, owner, repo, err := repos.ParseURL(msg.Name)
+	if err != nil {
+		m.dialog = dialog.NewOKDialog(err.Error(), config.DialogWidth)
+		return nil
+	}
+
+	path := msg.Path
+	if path == "" {
+		path = m.clonePath(msg.Name)
+	}
+	owner = owner[strings.LastIndex(owner, "/")+1:]
+
+	m.cloneData = map[string]any{
+		"name":    strings.ToLower(repo),
+		"owner":   strings.ToLower(owner),
+		"path":    path,
+		"command": msg.Command,
+	}
+	m.cloneProgress = ""
+	m.cloner = repos.Clone(msg.Name, path)
+	return m.cloner.Next()
+}
+
+func (m *Model) updateClone(msg repos.CloneMsg) tea.Cmd {
+	// drop anything from a clone that was cancelled
+	if msg.Cloner != m.cloner {
+		return nil
+	}
+	m.cloneProgress = msg.Progress
+	if !msg.Done {
+		return m.cloner.Next()
+	}
+
+	m.cloner = nil
+	if msg.Err != nil {
+		m.dialog = dialog.NewOKDialog(msg.Err.Error(), config.DialogWidth)
+		return nil
+	}
+	return m.callback(m.cloneData, m.config.CreateSessionKubeConfig)
+}
+
+func (m *Model) cancelClone() {
+	m.cloner.Cancel()
+	m.cloner = nil
+}
+
+func (m *Model) viewClone(doc string) string {
+	width := cloneWidth - 4
+	progress := m.cloneProgress
+	if progress == "" {
+		progress = "Waiting for " + m.cloner.URL
+	}
+	box := m.styles.viewport.Width(cloneWidth).Padding(0, 1).Render(lipgloss.JoinVertical(lipgloss.Left,
+		m.styles.title.Render("Cloning into "+ansi.Truncate(m.cloner.Path, width-14, string(icons.Ellipsis))),
+		m.styles.text.PaddingLeft(1).Render(ansi.Truncate(progress, width, string(icons.Ellipsis))),
+	))
+	return overlay.PlaceOverlay(m.width/2-cloneWidth/2, 6, box, doc, false)
+}
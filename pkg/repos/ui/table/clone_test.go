@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package table
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mproffitt/bmx/pkg/components/createpanel"
+	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/repos"
+)
+
+// Create a bare repository at <dir>/<owner>/<name>.git with a
+// single commit and return its file url
+func newBareRepo(t *testing.T, owner, name string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	source := t.TempDir()
+	bare := filepath.Join(t.TempDir(), owner, name+".git")
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main", source},
+		{"-C", source, "-c", "user.name=bmx", "-c", "user.email=bmx@example.com",
+			"commit", "-q", "--allow-empty", "-m", "initial"},
+		{"clone", "-q", "--bare", source, bare},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return "file://" + bare
+}
+
+func TestCloneOpensSession(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	remote := newBareRepo(t, "Owner", "Project")
+
+	var (
+		data       map[string]any
+		kubeconfig bool
+	)
+	root := filepath.Join(home, "src")
+	m := New(&config.Config{
+		Paths:                   []string{root},
+		CloneLayout:             "{owner}/{repo}",
+		CreateSessionKubeConfig: true,
+	}, func(d map[string]any, k bool) tea.Cmd {
+		data, kubeconfig = d, k
+		return nil
+	})
+	defer m.indexer.Wait()
+
+	want := filepath.Join(root, "Owner", "Project")
+	if path := m.clonePath(remote); path != want {
+		t.Fatalf("clone path = %q, want %q", path, want)
+	}
+
+	_, cmd := m.Update(createpanel.ObserverMsg{
+		Name:    remote,
+		Command: "nvim",
+		Focus:   createpanel.Button,
+	})
+	for cmd != nil {
+		msg, ok := cmd().(repos.CloneMsg)
+		if !ok {
+			t.Fatalf("got %T, want the clone progress", msg)
+		}
+		cmd = m.updateClone(msg)
+	}
+	if m.dialog != nil {
+		t.Fatalf("clone failed: %s", m.dialog.View())
+	}
+	if _, err := os.Stat(filepath.Join(want, repos.DefaultPattern)); err != nil {
+		t.Fatalf("repository was not cloned to %q: %v", want, err)
+	}
+
+	expected := map[string]any{
+		"name":    "project",
+		"owner":   "owner",
+		"path":    want,
+		"command": "nvim",
+	}
+	if len(data) != len(expected) {
+		t.Errorf("session data = %v, want %v", data, expected)
+	}
+	for k, v := range expected {
+		if data[k] != v {
+			t.Errorf("session %s = %v, want %v", k, data[k], v)
+		}
+	}
+	if !kubeconfig {
+		t.Error("session was not created with its own kubeconfig")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package table
+
+import (
+	"maps"
+
+	"github.com/evertras/bubble-table/table"
+	"github.com/mproffitt/bmx/pkg/components/matcher"
+)
+
+// Change what the table is filtered by
+//
+// The best match is highlighted whenever the filter changes
+func (m *Model) setFilter(filter string) {
+	if filter == m.filter {
+		return
+	}
+	m.filter = filter
+	m.drawTable()
+	m.table = m.table.WithHighlightedRow(0)
+}
+
+// Fuzzy match the filter against the name, owner and path of
+// each row
+//
+// Matches are highlighted in the name and owner columns and are
+// given a score so the best sort first
+func (m *Model) match(rows []table.Row) []table.Row {
+	if m.filter == "" {
+		return rows
+	}
+
+	items := make([][]string, len(rows))
+	for i, row := range rows {
+		name, _ := row.Data[columnKeyName].(string)
+		owner, _ := row.Data[columnKeyOwner].(string)
+		path, _ := row.Data[columnKeyPath].(string)
+		items[i] = []string{name, owner, path}
+	}
+
+	matches := matcher.Find(m.filter, items)
+	matched := make([]table.Row, 0, len(matches))
+	for _, match := range matches {
+		row := rows[match.Index]
+		row.Data = maps.Clone(row.Data)
+		row.Data[columnKeyShownName] = matcher.Highlight(items[match.Index][0], match.Matched[0])
+		row.Data[columnKeyShownOwner] = matcher.Highlight(items[match.Index][1], match.Matched[1])
+		row.Data[columnKeyScore] = match.Score
+		matched = append(matched, row)
+	}
+	return matched
+}
@@ -27,15 +27,20 @@ import (
 
 type keyMap struct {
 	All      key.Binding
+	Dirty    key.Binding
 	Down     key.Binding
 	Enter    key.Binding
 	Help     key.Binding
 	Pageup   key.Binding
 	Pagedown key.Binding
+	Preview  key.Binding
 	Quit     key.Binding
+	Refresh  key.Binding
 	ShiftTab key.Binding
+	Sort     key.Binding
 	Tab      key.Binding
 	Up       key.Binding
+	Worktree key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
@@ -52,12 +57,17 @@ func (k keyMap) FullHelp() [][]key.Binding {
 		{
 			k.Up, k.Down, k.Pageup, k.All, k.ShiftTab,
 		},
+		{
+			k.Refresh, k.Dirty, k.Sort, k.Worktree, k.Preview,
+		},
 	}
 }
 
 func mapKeys() keyMap {
 	return keyMap{
 		All: key.NewBinding(key.WithKeys("*"), key.WithHelp("*", "filter table")),
+		Dirty: key.NewBinding(key.WithKeys("ctrl+g"),
+			key.WithHelp("ctrl+g", "Only repositories with changes")),
 		Down: key.NewBinding(key.WithKeys("down"),
 			key.WithHelp("↓", "Move down")),
 		Enter: key.NewBinding(key.WithKeys("enter"),
@@ -68,14 +78,22 @@ func mapKeys() keyMap {
 			key.WithHelp("pgup", "Previous page")),
 		Pagedown: key.NewBinding(key.WithKeys("pgdown"),
 			key.WithHelp("pgdn", "Next page")),
+		Preview: key.NewBinding(key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "Toggle preview")),
 		Quit: key.NewBinding(key.WithKeys("esc", "ctrl+c"),
 			key.WithHelp("esc", "Quit")),
+		Refresh: key.NewBinding(key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "Reindex repositories")),
 		ShiftTab: key.NewBinding(key.WithKeys("shift+tab"),
 			key.WithHelp("shift+tab", "previous field")),
+		Sort: key.NewBinding(key.WithKeys("ctrl+o"),
+			key.WithHelp("ctrl+o", "Sort by frecency, name or last commit")),
 		Tab: key.NewBinding(key.WithKeys("tab"),
 			key.WithHelp("tab", "next field")),
 		Up: key.NewBinding(key.WithKeys("up"),
 			key.WithHelp("↑", "Move up")),
+		Worktree: key.NewBinding(key.WithKeys("ctrl+t"),
+			key.WithHelp("ctrl+t", "New worktree session")),
 	}
 }
 
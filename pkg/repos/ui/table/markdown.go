@@ -0,0 +1,132 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package table
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mproffitt/bmx/pkg/theme"
+	"github.com/muesli/reflow/wordwrap"
+)
+
+var (
+	markdownHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	markdownImage   = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLink    = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	markdownCode    = regexp.MustCompile("`([^`]+)`")
+	markdownBold    = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	markdownList    = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+)
+
+type markdownStyles struct {
+	code    lipgloss.Style
+	heading lipgloss.Style
+	link    lipgloss.Style
+	quote   lipgloss.Style
+}
+
+func newMarkdownStyles() markdownStyles {
+	return markdownStyles{
+		code:    lipgloss.NewStyle().Foreground(theme.Colours.Cyan),
+		heading: lipgloss.NewStyle().Foreground(theme.Colours.Yellow).Bold(true),
+		link:    lipgloss.NewStyle().Foreground(theme.Colours.Blue).Underline(true),
+		quote:   lipgloss.NewStyle().Foreground(theme.Colours.BrightBlack),
+	}
+}
+
+// Render markdown for the terminal, wrapped to width
+//
+// Only what reads well in a small pane is handled. Headings,
+// lists, quotes, code and links are styled, and images and html
+// are left out
+func renderMarkdown(text string, width int, styles markdownStyles) string {
+	lines := make([]string, 0)
+	blank := true
+	fenced := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			lines = append(lines, styles.code.Render("  "+strings.ReplaceAll(line, "\t", "  ")))
+			blank = false
+			continue
+		}
+
+		trimmed = strings.TrimSpace(markdownImage.ReplaceAllString(trimmed, ""))
+		if strings.HasPrefix(trimmed, "<") {
+			continue
+		}
+		if trimmed == "" {
+			// collapse runs of blank lines
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+
+		switch {
+		case markdownHeading.MatchString(trimmed):
+			heading := markdownHeading.FindStringSubmatch(trimmed)[2]
+			lines = append(lines, styles.heading.Render(wordwrap.String(heading, width)))
+		case strings.HasPrefix(trimmed, ">"):
+			quote := inline(strings.TrimSpace(strings.TrimLeft(trimmed, ">")), styles)
+			for _, l := range strings.Split(wordwrap.String(quote, width-2), "\n") {
+				lines = append(lines, styles.quote.Render("│ ")+l)
+			}
+		case markdownList.MatchString(line):
+			parts := markdownList.FindStringSubmatch(line)
+			indent := strings.Repeat(" ", min(len(parts[1]), 8))
+			item := inline(parts[3], styles)
+			for i, l := range strings.Split(wordwrap.String(item, width-len(indent)-2), "\n") {
+				bullet := "  "
+				if i == 0 {
+					bullet = "• "
+				}
+				lines = append(lines, indent+bullet+l)
+			}
+		case strings.Trim(trimmed, "-=*_ ") == "":
+			// rules and setext underlines
+			continue
+		default:
+			lines = append(lines, strings.Split(wordwrap.String(inline(trimmed, styles), width), "\n")...)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Style the inline code, links and bold text of a line
+func inline(line string, styles markdownStyles) string {
+	line = markdownCode.ReplaceAllStringFunc(line, func(s string) string {
+		return styles.code.Render(strings.Trim(s, "`"))
+	})
+	line = markdownLink.ReplaceAllStringFunc(line, func(s string) string {
+		return styles.link.Render(markdownLink.FindStringSubmatch(s)[1])
+	})
+	return markdownBold.ReplaceAllStringFunc(line, func(s string) string {
+		return lipgloss.NewStyle().Bold(true).Render(strings.Trim(s, "*_"))
+	})
+}
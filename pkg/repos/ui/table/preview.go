@@ -0,0 +1,169 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package table
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mproffitt/bmx/pkg/components/dialog"
+	"github.com/mproffitt/bmx/pkg/components/icons"
+	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/repos"
+	"github.com/mproffitt/bmx/pkg/tmux"
+)
+
+const (
+	// the preview is only shown when the table is at least this wide
+	minPreviewWidth = 120
+
+	// the share of the width given to the preview, out of 5
+	previewShare = 2
+)
+
+// The width of the preview pane, or zero when it is hidden
+func (m *Model) previewWidth() int {
+	if !m.previewShown || m.width < minPreviewWidth {
+		return 0
+	}
+	return m.width * previewShare / 5
+}
+
+// Read the preview of the highlighted repository if it
+// is shown and not already known
+//
+// Only one preview is read at a time. Once it arrives the
+// repository highlighted by then is read next
+func (m *Model) readPreview() tea.Cmd {
+	if m.spinner != nil || m.previewWidth() == 0 || m.previewing != "" {
+		return nil
+	}
+	path, _ := m.table.HighlightedRow().Data[columnKeyPath].(string)
+	if path == "" {
+		return nil
+	}
+	if _, ok := m.previews[path]; ok {
+		return nil
+	}
+	m.previewing = path
+	return repos.PreviewCmd(path)
+}
+
+func (m *Model) applyPreview(msg repos.PreviewMsg) {
+	m.previews[msg.Preview.Path] = msg.Preview
+	if m.previewing == msg.Preview.Path {
+		m.previewing = ""
+	}
+}
+
+// Render the preview of the highlighted repository
+//
+// The branch and any open session lead, followed by the recent
+// commits and the README, cut to fit the height of the pane
+func (m *Model) viewPreview(width, height int) string {
+	inner := width - 4
+	path, _ := m.table.HighlightedRow().Data[columnKeyPath].(string)
+	preview, ok := m.previews[path]
+
+	heading := m.styles.title.UnsetPadding()
+	lines := make([]string, 0)
+	switch {
+	case path == "":
+		lines = append(lines, m.styles.text.Render("Nothing selected"))
+	case !ok:
+		lines = append(lines, m.styles.text.Render("Reading "+path+string(icons.Ellipsis)))
+	default:
+		status := preview.Status
+		if s, ok := m.statuses[path]; ok {
+			status = s
+		}
+		if status.Branch != "" {
+			state := m.state(status)
+			lines = append(lines, heading.Render(status.Branch)+" "+state.Style.Render(state.Data.(string)))
+		}
+		for _, session := range preview.Sessions {
+			lines = append(lines, m.styles.clean.Render("Open in session "+session))
+		}
+
+		if len(preview.Commits) > 0 {
+			lines = append(lines, "", heading.Render("Recent commits"))
+			for _, commit := range preview.Commits {
+				lines = append(lines, fmt.Sprintf("%s %s %s",
+					m.styles.dirty.Render(commit.Hash),
+					m.styles.text.Render(fmt.Sprintf("%-4s", age(commit.When))),
+					commit.Subject))
+			}
+		}
+
+		if preview.Readme != "" {
+			lines = append(lines, "")
+			lines = append(lines, strings.Split(renderMarkdown(preview.Readme, inner, m.styles.markdown), "\n")...)
+		}
+	}
+
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, inner, string(icons.Ellipsis))
+	}
+	lines = lines[:min(len(lines), max(height-2, 0))]
+	return m.styles.viewport.
+		Width(width-2).
+		Height(height-2).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
+
+// The session already open in the path of the new session
+//
+// Nothing is given when the session would be attached to
+// anyway, as it has the name being created
+func openSession(data map[string]any) string {
+	path, _ := data["path"].(string)
+	name, _ := data["name"].(string)
+	sessions := tmux.SessionsAt(path)
+	if len(sessions) == 0 || slices.Contains(sessions, name) {
+		return ""
+	}
+	return sessions[0]
+}
+
+// Offer to switch to the session already open for the repository
+// rather than opening a second one
+func (m *Model) confirmSwitch(data map[string]any, session string) {
+	m.switchData = data
+	m.switchData["name"] = session
+	m.dialog = dialog.NewConfirmDialog(
+		fmt.Sprintf("%s is already open in session\n\n%s\n\nSwitch to it?",
+			data["path"], lipgloss.NewStyle().Bold(true).Render(session)),
+		config.DialogWidth)
+}
+
+// Switch to the open session once confirmed
+func (m *Model) switchSession(confirmed bool) tea.Cmd {
+	data := m.switchData
+	m.switchData = nil
+	if !confirmed {
+		return nil
+	}
+	return m.callback(data, m.config.CreateSessionKubeConfig)
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package table
+
+import (
+	"slices"
+
+	"github.com/evertras/bubble-table/table"
+	"github.com/mproffitt/bmx/pkg/repos"
+)
+
+// Add the directories found by the extra sources to the table
+//
+// Directories already in the table keep their row and only take the
+// higher of the two scores. Both are scaled to between 0 and 1 so
+// neither source outweighs the other. Source rows are replaced by the index if
+// it later finds the same repository
+func (m *Model) applySources(msg repos.SourcesMsg) {
+	for _, directory := range msg.Directories {
+		m.scores[directory.Path] = max(m.scores[directory.Path], directory.Score)
+		index := slices.IndexFunc(m.rows, func(r table.Row) bool {
+			return r.Data[columnKeyPath] == directory.Path
+		})
+		if index >= 0 {
+			m.rows[index].Data[columnKeyFrecency] = m.scores[directory.Path]
+			continue
+		}
+
+		rows := m.newRows(directory.Repository)
+		for _, row := range rows {
+			row.Data[columnKeySource] = directory.Source
+		}
+		m.rows = append(m.rows, rows...)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package table
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/evertras/bubble-table/table"
+	"github.com/mproffitt/bmx/pkg/repos"
+	"github.com/mproffitt/bmx/pkg/tmux/ui/manager"
+)
+
+// Git status is only read for rows on the current page unless
+// it is needed to filter or sort the whole table, in which
+// case it is read in batches of this size
+const statusBatch = 20
+
+// Read the status of any repositories that need it and are not
+// already known or being read
+func (m *Model) readStatuses() tea.Cmd {
+	if m.spinner != nil {
+		return nil
+	}
+
+	var rows []table.Row
+	switch {
+	case m.dirtyOnly || m.order == manager.Oldest:
+		rows = m.rows
+	default:
+		visible := m.table.GetVisibleRows()
+		start, end := m.table.VisibleIndices()
+		if start >= len(visible) {
+			return nil
+		}
+		rows = visible[start : end+1]
+	}
+
+	paths := make([]string, 0)
+	for _, row := range rows {
+		path, _ := row.Data[columnKeyPath].(string)
+		if _, ok := m.statuses[path]; ok || m.pending[path] {
+			continue
+		}
+		m.pending[path] = true
+		paths = append(paths, path)
+	}
+
+	cmds := make([]tea.Cmd, 0)
+	for batch := range slices.Chunk(paths, statusBatch) {
+		cmds = append(cmds, repos.StatusCmd(batch))
+	}
+	return tea.Batch(cmds...)
+}
+
+// Store the statuses that have been read and update their rows
+//
+// Repositories that could not be read stay pending so they are
+// not read again until the table is next opened
+func (m *Model) applyStatuses(msg repos.StatusMsg) {
+	for path, status := range msg.Statuses {
+		m.statuses[path] = status
+	}
+	for i, row := range m.rows {
+		path, _ := row.Data[columnKeyPath].(string)
+		if _, ok := msg.Statuses[path]; ok {
+			m.rows[i] = m.withStatus(row)
+		}
+	}
+}
+
+// Add the status columns to a row
+//
+// Rows without a status yet are left blank and sort as
+// the oldest commits
+func (m *Model) withStatus(row table.Row) table.Row {
+	path, _ := row.Data[columnKeyPath].(string)
+	status, ok := m.statuses[path]
+	if !ok {
+		row.Data[columnKeyBranch] = ""
+		row.Data[columnKeyState] = ""
+		row.Data[columnKeyAge] = ""
+		row.Data[columnKeyCommitted] = int64(0)
+		return row
+	}
+
+	row.Data[columnKeyBranch] = status.Branch
+	row.Data[columnKeyState] = m.state(status)
+	row.Data[columnKeyAge] = age(status.LastCommit)
+	row.Data[columnKeyCommitted] = status.LastCommit.Unix()
+	return row
+}
+
+// The rows shown in the table after the status filter and any
+// typed filter are applied
+//
+// Repositories that have not been read yet are hidden until
+// they are known to have changes
+func (m *Model) shownRows() []table.Row {
+	if !m.dirtyOnly {
+		return m.match(m.rows)
+	}
+	rows := make([]table.Row, 0)
+	for _, row := range m.rows {
+		path, _ := row.Data[columnKeyPath].(string)
+		if status, ok := m.statuses[path]; ok && status.Dirty {
+			rows = append(rows, row)
+		}
+	}
+	return m.match(rows)
+}
+
+// Render the working tree state, upstream distance and stash count
+func (m *Model) state(status repos.Status) table.StyledCell {
+	parts := make([]string, 0)
+	style := m.styles.clean
+	if status.Dirty {
+		parts = append(parts, "●")
+		style = m.styles.dirty
+	} else {
+		parts = append(parts, "✓")
+	}
+
+	if status.Ahead > 0 || status.Behind > 0 {
+		var distance string
+		if status.Ahead > 0 {
+			distance += fmt.Sprintf("↑%d", status.Ahead)
+		}
+		if status.Behind > 0 {
+			distance += fmt.Sprintf("↓%d", status.Behind)
+		}
+		parts = append(parts, distance)
+	}
+
+	if status.Stashes > 0 {
+		parts = append(parts, fmt.Sprintf("≡%d", status.Stashes))
+	}
+	return table.NewStyledCell(strings.Join(parts, " "), style)
+}
+
+// Format the time since the last commit
+func age(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := time.Since(t)
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 60*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	default:
+		return fmt.Sprintf("%dmo", int(d.Hours()/24/30))
+	}
+}
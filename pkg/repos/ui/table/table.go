@@ -20,9 +20,7 @@
 package table
 
 import (
-	"fmt"
-	"os"
-	"sync"
+	"slices"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -32,10 +30,13 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/evertras/bubble-table/table"
 	"github.com/mproffitt/bmx/pkg/components/createpanel"
+	"github.com/mproffitt/bmx/pkg/components/icons"
 	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/frecency"
 	"github.com/mproffitt/bmx/pkg/helpers"
 	"github.com/mproffitt/bmx/pkg/repos"
 	"github.com/mproffitt/bmx/pkg/theme"
+	"github.com/mproffitt/bmx/pkg/tmux/ui/manager"
 )
 
 var customBorder = table.Border{
@@ -64,10 +65,30 @@ const (
 	columnKeyUrl   = "url"
 	columnKeyPath  = "path"
 
+	columnKeyAge       = "age"
+	columnKeyBranch    = "branch"
+	columnKeyCommitted = "committed"
+	columnKeyFrecency  = "frecency"
+	columnKeySource    = "source"
+	columnKeyState     = "state"
+
+	// the name and owner as shown, with any matches highlighted,
+	// and how well they matched
+	columnKeyScore      = "score"
+	columnKeyShownName  = "shownName"
+	columnKeyShownOwner = "shownOwner"
+
+	// worktrees are grouped under their repository
+	columnKeyChild   = "child"
+	columnKeyGroup   = "group"
+	columnKeyParent  = "parent"
+	columnKeySession = "session"
+
+	ageWidth            = 4
 	maxWidth            = 20
 	minHeight           = 10
 	fixedVerticalMargin = 4
-	pattern             = ".git/config"
+	pattern             = repos.DefaultPattern
 )
 
 type InputFocus int
@@ -80,26 +101,50 @@ const (
 )
 
 type Model struct {
-	sync.Mutex
-
+	branch    textinput.Model
+	branching bool
 	callback  func(map[string]any, bool) tea.Cmd
+	cloner    *repos.Cloner
 	columns   []table.Column
 	config    *config.Config
 	current   *textinput.Model
 	dialog    tea.Model
+	dirtyOnly bool
+	filter    string
 	inputs    inputs
 	focus     InputFocus
 	height    int
+	indexer   *repos.Indexer
+	indexing  bool
 	isOverlay bool
 	keymap    keyMap
+	order     manager.SortBy
 	panel     *createpanel.Model
 	paths     []string
+	pending   map[string]bool
+	previews  map[string]repos.Preview
 	rows      []table.Row
+	scores    map[string]float64
 	spinner   *spinner.Model
+	statuses  map[string]repos.Status
 	styles    styles
 	table     table.Model
 	viewport  viewport.Model
 	width     int
+
+	// the repository a worktree is being created for
+	worktreeFor table.RowData
+
+	// the preview pane and the path it is being read for
+	previewShown bool
+	previewing   string
+
+	// the session to switch to once confirmed
+	switchData map[string]any
+
+	// the session to open once cloning is done
+	cloneData     map[string]any
+	cloneProgress string
 }
 
 type inputs struct {
@@ -111,7 +156,10 @@ type inputs struct {
 type styles struct {
 	activeButton lipgloss.Style
 	button       lipgloss.Style
+	clean        lipgloss.Style
+	dirty        lipgloss.Style
 	filter       lipgloss.Style
+	markdown     markdownStyles
 	spinner      lipgloss.Style
 	table        lipgloss.Style
 	text         lipgloss.Style
@@ -129,13 +177,25 @@ func New(config *config.Config, callback func(map[string]any, bool) tea.Cmd) *Mo
 			path:    textinput.New(),
 			command: textinput.New(),
 		},
-		focus:   Filter,
-		keymap:  mapKeys(),
-		paths:   config.Paths,
-		spinner: &spinner,
+		focus:    Filter,
+		keymap:   mapKeys(),
+		order:    manager.Frecency,
+		paths:    config.Paths,
+		pending:  make(map[string]bool),
+		previews: make(map[string]repos.Preview),
+		scores:   frecency.Normalise(frecency.Scores(frecency.Repositories)),
+		spinner:  &spinner,
+		statuses: make(map[string]repos.Status),
+
+		previewShown: true,
 		styles: styles{
+			markdown: newMarkdownStyles(),
 			table: lipgloss.NewStyle().
 				BorderForeground(theme.Colours.Black),
+			clean: lipgloss.NewStyle().
+				Foreground(theme.Colours.Green),
+			dirty: lipgloss.NewStyle().
+				Foreground(theme.Colours.Yellow),
 			spinner: lipgloss.NewStyle().
 				Foreground(theme.Colours.Purple),
 			text: lipgloss.NewStyle().
@@ -185,8 +245,10 @@ func New(config *config.Config, callback func(map[string]any, bool) tea.Cmd) *Mo
 
 	model.spinner.Style = model.styles.spinner
 
-	// load repo paths in the background
-	go model.loadData(model.paths)
+	// show the index straight away and check it in the background
+	model.setRows(repos.LoadIndex(model.paths))
+	model.indexer = repos.Reindex(model.paths, pattern, false)
+	model.indexing = true
 	return model
 }
 
@@ -195,16 +257,24 @@ func (m *Model) GetSize() (int, int) {
 }
 
 func (m *Model) Init() tea.Cmd {
-	return m.spinner.Tick
+	cmds := []tea.Cmd{m.indexer.Next(), repos.SourcesCmd(m.config)}
+	if m.spinner != nil {
+		cmds = append(cmds, m.spinner.Tick)
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *Model) Overlay() helpers.UseOverlay {
 	m.isOverlay = true
+	// show the indexed repositories without waiting for the spinner
+	if m.spinner != nil {
+		m.drawTable()
+	}
 	return m
 }
 
 func (m *Model) HasActiveDialog() bool {
-	return m.dialog != nil
+	return m.dialog != nil || m.branching || m.cloner != nil
 }
 
 func (m *Model) SetSize(width, height int) {
@@ -224,7 +294,7 @@ func (m *Model) getSuggestions(msg *createpanel.SuggestionsMsg) {
 		ok           bool
 	)
 
-	if filter, ok = values[columnKeyName].(string); ok {
+	if filter = sessionName(selected); filter != "" {
 		(*msg).Name = []string{filter}
 	}
 
@@ -233,29 +303,111 @@ func (m *Model) getSuggestions(msg *createpanel.SuggestionsMsg) {
 	}
 }
 
-func (m *Model) loadData(paths []string) {
-	m.rows = make([]table.Row, 0)
-	repositories, err := repos.Find(paths, pattern)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "err : %+v\n", err)
+func (m *Model) setRows(repositories []repos.Repository) {
+	m.rows = make([]table.Row, 0, len(repositories))
+	for _, repo := range repositories {
+		m.rows = append(m.rows, m.newRows(repo)...)
 	}
+}
 
-	m.Lock()
-	for _, repo := range repositories {
-		m.rows = append(m.rows,
-			table.NewRow(table.RowData{
-				columnKeyName:  repo.Name,
-				columnKeyOwner: repo.Owner,
-				columnKeyUrl:   repo.Url,
-				columnKeyPath:  repo.Path,
-			}),
-		)
+// Create the row for a repository followed by a row for each of its
+// worktrees
+//
+// Worktree rows sort under their repository and carry the session
+// name separately as the name column is indented to show them as
+// children
+func (m *Model) newRows(repo repos.Repository) []table.Row {
+	// projects without a remote show what they were found by
+	url := repo.Url
+	if url == "" {
+		url = repo.Kind
+	}
+	rows := []table.Row{
+		m.withStatus(table.NewRow(table.RowData{
+			columnKeyName:       repo.Name,
+			columnKeyOwner:      repo.Owner,
+			columnKeyShownName:  repo.Name,
+			columnKeyShownOwner: repo.Owner,
+			columnKeyUrl:        url,
+			columnKeyPath:       repo.Path,
+			columnKeyGroup:      repo.Name,
+			columnKeyChild:      0,
+
+			columnKeyFrecency: m.scores[repo.Path],
+		})),
+	}
+	for _, worktree := range repo.Worktrees {
+		session := repos.SessionName(repo.Name, worktree.Branch)
+		name := string(icons.Child) + " " + session
+		rows = append(rows, m.withStatus(table.NewRow(table.RowData{
+			columnKeyName:       name,
+			columnKeyOwner:      repo.Owner,
+			columnKeyShownName:  name,
+			columnKeyShownOwner: repo.Owner,
+			columnKeyUrl:        repo.Url,
+			columnKeyPath:       worktree.Path,
+			columnKeyGroup:      repo.Name,
+			columnKeyChild:      1,
+			columnKeyParent:     repo.Path,
+			columnKeySession:    session,
+
+			columnKeyFrecency: m.scores[worktree.Path],
+		})))
+	}
+	return rows
+}
+
+// Apply the changes found by the indexer to the table rows
+//
+// Moved repositories arrive as a new path and are removed
+// from their old path as soon as the indexer finds it gone
+func (m *Model) applyIndex(msg repos.IndexMsg) {
+	for _, repo := range msg.Found {
+		index := slices.IndexFunc(m.rows, func(r table.Row) bool {
+			return r.Data[columnKeyPath] == repo.Path
+		})
+		m.rows = slices.DeleteFunc(m.rows, func(r table.Row) bool {
+			return belongsTo(r, []string{repo.Path})
+		})
+		if index < 0 || index > len(m.rows) {
+			index = len(m.rows)
+		}
+		m.rows = slices.Insert(m.rows, index, m.newRows(repo)...)
+	}
+	m.rows = slices.DeleteFunc(m.rows, func(r table.Row) bool {
+		return belongsTo(r, msg.Removed)
+	})
+}
+
+// Is the row one of the repositories at paths or one of their worktrees
+func belongsTo(row table.Row, paths []string) bool {
+	path, _ := row.Data[columnKeyPath].(string)
+	parent, _ := row.Data[columnKeyParent].(string)
+	return slices.Contains(paths, path) || slices.Contains(paths, parent)
+}
+
+// The name to give a session created from the row
+func sessionName(row table.RowData) string {
+	if name, ok := row[columnKeySession].(string); ok {
+		return name
 	}
-	m.Unlock()
+	name, _ := row[columnKeyName].(string)
+	return name
+}
+
+// Start reading every repository again
+func (m *Model) reindex() tea.Cmd {
+	m.indexer = repos.Reindex(m.paths, pattern, true)
+	m.indexing = true
+	return m.indexer.Next()
 }
 
+// Draw the table once there is something to show
+//
+// Once drawn, the columns and rows are replaced in place so the
+// filter and highlighted row survive changes from the indexer
 func (m *Model) drawTable() {
-	if len(m.rows) == 0 {
+	if len(m.rows) == 0 && m.indexing {
 		return
 	}
 
@@ -263,9 +415,9 @@ func (m *Model) drawTable() {
 	if m.isOverlay {
 		subtract = 12
 	}
-	maxName, maxOwner, maxUrl := 0, 0, 0
+	maxName, maxOwner, maxBranch, maxState, maxSource := 0, 0, 0, 0, 0
 	for _, row := range m.rows {
-		nameLen := len(row.Data[columnKeyName].(string))
+		nameLen := lipgloss.Width(row.Data[columnKeyName].(string))
 		if nameLen > maxName {
 			maxName = nameLen
 		}
@@ -275,20 +427,52 @@ func (m *Model) drawTable() {
 			maxOwner = ownerLen
 			maxOwner = min(maxOwner, maxWidth)
 		}
-		// w := m.styles.table.GetHorizontalFrameSize()
-		maxUrl = m.width - (maxName + maxOwner) - subtract
+
+		branchLen := lipgloss.Width(row.Data[columnKeyBranch].(string))
+		maxBranch = min(max(maxBranch, branchLen), maxWidth)
+
+		if state, ok := row.Data[columnKeyState].(table.StyledCell); ok {
+			maxState = max(maxState, lipgloss.Width(state.Data.(string)))
+		}
+
+		if source, ok := row.Data[columnKeySource].(string); ok {
+			maxSource = max(maxSource, len(source))
+		}
 	}
+	// w := m.styles.table.GetHorizontalFrameSize()
+	maxUrl := m.width - m.previewWidth() - (maxName + maxOwner) - subtract
 
+	// status and source columns are only shown once something
+	// is known and take their space from the url
 	m.columns = []table.Column{
-		table.NewColumn(columnKeyName, "Name", maxName).WithFiltered(true),
-		table.NewColumn(columnKeyOwner, "Owner", maxOwner),
-		table.NewColumn(columnKeyUrl, "Url", maxUrl),
+		table.NewColumn(columnKeyShownName, "Name", maxName),
+		table.NewColumn(columnKeyShownOwner, "Owner", maxOwner),
+	}
+	if maxBranch > 0 {
+		maxUrl -= maxBranch + 1
+		m.columns = append(m.columns, table.NewColumn(columnKeyBranch, "Branch", maxBranch+1))
+	}
+	if maxState > 0 {
+		maxUrl -= maxState + ageWidth + 2
+		m.columns = append(m.columns,
+			table.NewColumn(columnKeyState, "State", maxState+1),
+			table.NewColumn(columnKeyAge, "Age", ageWidth+1),
+		)
+	}
+	if maxSource > 0 {
+		maxUrl -= maxSource + 1
+		m.columns = append(m.columns, table.NewColumn(columnKeySource, "Source", maxSource+1))
+	}
+	m.columns = append(m.columns, table.NewColumn(columnKeyUrl, "Url", maxUrl))
+
+	if m.spinner == nil {
+		m.table = m.sort(m.table.WithColumns(m.columns).WithRows(m.shownRows()))
+		return
 	}
 
 	pageSize := max(19, m.height-subtract)
 	m.table = table.New(m.columns).
 		Border(customBorder).
-		Filtered(true).
 		Focused(true).
 		WithBaseStyle(lipgloss.NewStyle().
 			BorderForeground(theme.Colours.Black).
@@ -302,12 +486,36 @@ func (m *Model) drawTable() {
 		WithFooterVisibility(false).
 		WithHeaderVisibility(false).
 		WithPageSize(pageSize).
-		WithRows(m.rows).
-		SortByAsc(columnKeyOwner).ThenSortByAsc(columnKeyName)
+		WithRows(m.shownRows())
+	m.table = m.sort(m.table)
 
 	m.spinner = nil
 }
 
+// Sort by most recent commit, or by owner and name with the
+// most used repositories first when sorting by frecency
+//
+// While filtering the best matches always come first
+func (m *Model) sort(t table.Model) table.Model {
+	if m.filter != "" {
+		return t.SortByDesc(columnKeyScore)
+	}
+	switch m.order {
+	case manager.Oldest:
+		return t.SortByDesc(columnKeyCommitted)
+	case manager.Frecency:
+		return t.SortByDesc(columnKeyFrecency).
+			ThenSortByAsc(columnKeyOwner).
+			ThenSortByAsc(columnKeyGroup).
+			ThenSortByAsc(columnKeyChild).
+			ThenSortByAsc(columnKeyName)
+	}
+	return t.SortByAsc(columnKeyOwner).
+		ThenSortByAsc(columnKeyGroup).
+		ThenSortByAsc(columnKeyChild).
+		ThenSortByAsc(columnKeyName)
+}
+
 func (m *Model) getInputKeyMap() textinput.KeyMap {
 	return textinput.KeyMap{
 		CharacterForward:        key.NewBinding(key.WithKeys("right", "ctrl+f")),
@@ -27,6 +27,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mproffitt/bmx/pkg/components/createpanel"
 	"github.com/mproffitt/bmx/pkg/components/dialog"
+	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/repos"
 )
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -53,6 +56,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// when we recieve this message then
 		// we handle the creation of the new
 		// session
+		if msg.Focus == createpanel.Button && repos.IsURL(msg.Name) {
+			return m, m.clone(msg)
+		}
 		if msg.Focus == createpanel.Button {
 			current := m.table.HighlightedRow().Data
 			data := make(map[string]any)
@@ -60,6 +66,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				for col := range current {
 					data[col] = current[col]
 				}
+				data["name"] = sessionName(current)
 			}
 			data["path"] = msg.Path
 			data["command"] = msg.Command
@@ -70,6 +77,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				data["name"] = msg.Name
 			}
+			if session := openSession(data); session != "" {
+				m.confirmSwitch(data, session)
+				return m, nil
+			}
 
 			// TODO: Convert callback to tea.Msg
 			// This was written during the very first incarnation of the application
@@ -81,17 +92,22 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		var name, path string
 		{
-			if data, ok := m.table.HighlightedRow().Data[columnKeyName]; ok {
-				name = data.(string)
-			}
+			name = sessionName(m.table.HighlightedRow().Data)
 			if data, ok := m.table.HighlightedRow().Data[columnKeyPath]; ok {
 				path = data.(string)
 			}
 
 		}
+		// A remote entered as the name is cloned so
+		// show where it will go in place of the path
+		remote := repos.IsURL(msg.Name)
+		if remote {
+			path = m.clonePath(msg.Name)
+		}
+
 		if msg.Focus == createpanel.Name {
 			if msg.Name != name {
-				m.table = m.table.WithFilterInputValue(msg.Name)
+				m.setFilter(msg.Name)
 			}
 			msg.Path = path
 		}
@@ -102,8 +118,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// next enter keypress creates the session
 		if msg.LastKey.String() == "enter" {
 			msg.Focus = createpanel.Button
-			msg.Name = name
-			msg.Path = path
+			if !remote {
+				msg.Name = name
+				msg.Path = path
+			}
 		}
 		var model tea.Model
 		model, cmd = m.panel.Update(msg)
@@ -119,6 +137,15 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.dialog, cmd = m.dialog.Update(msg)
 			return m, cmd
 		}
+		if m.branching {
+			return m, m.updateWorktree(msg)
+		}
+		if m.cloner != nil {
+			if key.Matches(msg, m.keymap.Quit) {
+				m.cancelClone()
+			}
+			return m, nil
+		}
 
 		switch {
 		case key.Matches(msg, m.keymap.Quit):
@@ -133,15 +160,60 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.displayHelp()
 		case key.Matches(msg, m.keymap.Pagedown, m.keymap.Pageup):
 			m.table, _ = m.table.Update(msg)
+		case key.Matches(msg, m.keymap.Refresh):
+			cmds = append(cmds, m.reindex())
+		case key.Matches(msg, m.keymap.Worktree):
+			cmds = append(cmds, m.startWorktree())
+		case key.Matches(msg, m.keymap.Dirty):
+			m.dirtyOnly = !m.dirtyOnly
+			m.drawTable()
+		case key.Matches(msg, m.keymap.Sort):
+			m.order = m.order.Toggle()
+			m.drawTable()
+		case key.Matches(msg, m.keymap.Preview):
+			m.previewShown = !m.previewShown
+			m.drawTable()
 		default:
 			var model tea.Model
 			model, cmd = m.panel.Update(msg)
 			m.panel = model.(*createpanel.Model)
 			cmds = append(cmds, cmd)
 		}
+	case repos.IndexMsg:
+		// drop anything still in flight from a replaced indexer
+		if msg.Indexer != m.indexer {
+			break
+		}
+		m.applyIndex(msg)
+		if msg.Done {
+			m.indexing = false
+		} else {
+			cmds = append(cmds, m.indexer.Next())
+		}
+		if m.spinner == nil || !m.indexing {
+			m.drawTable()
+		}
+	case repos.SourcesMsg:
+		m.applySources(msg)
+		if m.spinner == nil || !m.indexing {
+			m.drawTable()
+		}
+	case repos.CloneMsg:
+		cmds = append(cmds, m.updateClone(msg))
+	case repos.StatusMsg:
+		m.applyStatuses(msg)
+		m.drawTable()
+	case repos.PreviewMsg:
+		m.applyPreview(msg)
+	case helpers.ErrorMsg:
+		// only seen when run outside the session manager
+		m.dialog = dialog.NewOKDialog(msg.Error.Error(), config.DialogWidth)
 	case dialog.DialogStatusMsg:
 		if msg.Done {
 			m.dialog = nil
+			if m.switchData != nil {
+				cmds = append(cmds, m.switchSession(msg.Selected == dialog.Confirm))
+			}
 		}
 	case spinner.TickMsg:
 		if m.spinner != nil {
@@ -158,5 +230,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.table, cmd = m.table.Update(msg)
 		cmds = append(cmds, cmd)
 	}
+
+	// read the status of anything newly visible
+	// and the preview of the highlighted repository
+	cmds = append(cmds, m.readStatuses(), m.readPreview())
 	return m, tea.Batch(cmds...)
 }
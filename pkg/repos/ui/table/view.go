@@ -26,8 +26,10 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mproffitt/bmx/pkg/components/dialog"
+	"github.com/mproffitt/bmx/pkg/components/icons"
 	"github.com/mproffitt/bmx/pkg/components/overlay"
 	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/tmux/ui/manager"
 )
 
 func (m *Model) View() string {
@@ -41,8 +43,20 @@ func (m *Model) View() string {
 
 	body := strings.Builder{}
 	if m.isOverlay {
-		title := m.styles.title.Render("Create new session\n")
-		body.WriteString(title)
+		title := "Create new session"
+		if m.dirtyOnly {
+			title += m.styles.text.Render(" with changes")
+		}
+		switch m.order {
+		case manager.Name:
+			title += m.styles.text.Render(" by name")
+		case manager.Oldest:
+			title += m.styles.text.Render(" by last commit")
+		}
+		if m.indexing {
+			title += m.styles.text.Render(" (indexing" + string(icons.Ellipsis) + ")")
+		}
+		body.WriteString(m.styles.title.Render(title + "\n"))
 	}
 
 	subtract := 9
@@ -51,9 +65,14 @@ func (m *Model) View() string {
 	}
 	var content string
 	{
-		viewport := viewport.New(m.width-4, m.height-subtract)
+		width := m.previewWidth()
+		viewport := viewport.New(m.width-width-4, m.height-subtract)
 		viewport.SetContent(m.table.View())
 		content = m.styles.viewport.Padding(0, 0, 1, 2).Render(viewport.View())
+		if width > 0 {
+			content = lipgloss.JoinHorizontal(lipgloss.Top, content,
+				m.viewPreview(width, lipgloss.Height(content)))
+		}
 		body.WriteString(content + "\n")
 	}
 	m.panel.SetWidth(m.width)
@@ -61,6 +80,12 @@ func (m *Model) View() string {
 	body.WriteString(m.panel.View())
 
 	doc := m.styles.table.Render(body.String())
+	if m.branching {
+		doc = m.viewWorktree(doc)
+	}
+	if m.cloner != nil {
+		doc = m.viewClone(doc)
+	}
 	if m.dialog != nil {
 		dw, _ := m.dialog.(*dialog.Dialog).GetSize()
 		w := m.width/2 - max(dw, config.DialogWidth)/2
@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package table
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/evertras/bubble-table/table"
+	"github.com/mproffitt/bmx/pkg/components/overlay"
+	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/repos"
+)
+
+const worktreeWidth = 50
+
+// Ask for the branch to create a worktree for from the
+// highlighted repository or one of its worktrees
+func (m *Model) startWorktree() tea.Cmd {
+	row := m.table.HighlightedRow().Data
+	if row == nil {
+		return nil
+	}
+	path, _ := row[columnKeyPath].(string)
+	if parent, ok := row[columnKeyParent].(string); ok {
+		path = parent
+	}
+	name, _ := row[columnKeyGroup].(string)
+
+	m.worktreeFor = table.RowData{
+		columnKeyName:  name,
+		columnKeyOwner: row[columnKeyOwner],
+		columnKeyPath:  path,
+	}
+	m.branch = textinput.New()
+	m.branch.Placeholder = "branch"
+	m.branch.Width = worktreeWidth - 8
+	m.branch.ShowSuggestions = true
+	m.branch.SetSuggestions(repos.Branches(path))
+	m.branching = true
+	return m.branch.Focus()
+}
+
+func (m *Model) updateWorktree(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.branching = false
+		return nil
+	case "enter":
+		if m.branch.Value() == "" {
+			return nil
+		}
+		m.branching = false
+		return m.createWorktree(m.branch.Value())
+	}
+	var cmd tea.Cmd
+	m.branch, cmd = m.branch.Update(msg)
+	return cmd
+}
+
+// Create the worktree and open a session in it named `<repo>@<branch>`
+func (m *Model) createWorktree(branch string) tea.Cmd {
+	var (
+		name, _  = m.worktreeFor[columnKeyName].(string)
+		owner, _ = m.worktreeFor[columnKeyOwner].(string)
+		path, _  = m.worktreeFor[columnKeyPath].(string)
+		callback = m.callback
+		kube     = m.config.CreateSessionKubeConfig
+	)
+	return func() tea.Msg {
+		worktree, err := repos.AddWorktree(path, branch)
+		if err != nil {
+			return helpers.ErrorMsg{Error: err}
+		}
+		return callback(map[string]any{
+			"name":    repos.SessionName(name, branch),
+			"owner":   owner,
+			"path":    worktree,
+			"command": "",
+		}, kube)()
+	}
+}
+
+func (m *Model) viewWorktree(doc string) string {
+	name, _ := m.worktreeFor[columnKeyName].(string)
+	box := m.styles.viewport.Width(worktreeWidth).Render(lipgloss.JoinVertical(lipgloss.Left,
+		m.styles.title.Render("New worktree of "+name),
+		m.styles.filter.Width(worktreeWidth-2).Render(m.branch.View()),
+	))
+	return overlay.PlaceOverlay(m.width/2-worktreeWidth/2, 6, box, doc, false)
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package repos
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mproffitt/bmx/pkg/exec"
+	"github.com/mproffitt/bmx/pkg/tmux"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+// Worktree is a linked working tree of a repository
+//
+// Branch is the short hash of the commit when the
+// worktree has a detached HEAD
+type Worktree struct {
+	Branch string `yaml:"branch"`
+	Path   string `yaml:"path"`
+}
+
+// SessionName gives the session name used for a worktree of a repository
+//
+// tmux does not allow `.` or `:` in session names so these are replaced
+func SessionName(repo, branch string) string {
+	return strings.NewReplacer(".", "_", ":", "_").Replace(repo + "@" + branch)
+}
+
+// List the linked worktrees of the repository at path
+//
+// These are read from `.git/worktrees` rather than asking git so
+// indexing does not need to run a command for every repository.
+// Worktrees whose directory has gone are skipped
+func worktrees(path string) []Worktree {
+	admin := filepath.Join(path, ".git", "worktrees")
+	entries, err := os.ReadDir(admin)
+	if err != nil {
+		return nil
+	}
+
+	list := make([]Worktree, 0, len(entries))
+	for _, entry := range entries {
+		dir := filepath.Join(admin, entry.Name())
+		gitdir, err := os.ReadFile(filepath.Join(dir, "gitdir"))
+		if err != nil {
+			continue
+		}
+		worktree := filepath.Dir(strings.TrimSpace(string(gitdir)))
+		if _, err := os.Stat(worktree); err != nil {
+			continue
+		}
+
+		head, err := os.ReadFile(filepath.Join(dir, "HEAD"))
+		if err != nil {
+			continue
+		}
+		branch := strings.TrimSpace(string(head))
+		if ref, ok := strings.CutPrefix(branch, "ref: "); ok {
+			branch = plumbing.ReferenceName(ref).Short()
+		} else if len(branch) > 7 {
+			branch = branch[:7]
+		}
+		list = append(list, Worktree{Branch: branch, Path: worktree})
+	}
+	slices.SortFunc(list, func(a, b Worktree) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return list
+}
+
+// Branches lists the local and remote branches of the repository at
+// path for use as suggestions when creating a worktree
+//
+// Remote branches are given without the remote name and branches
+// that are already checked out somewhere are included
+func Branches(path string) []string {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return nil
+	}
+	refs, err := repo.References()
+	if err != nil {
+		return nil
+	}
+
+	branches := make([]string, 0)
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		switch {
+		case name.IsBranch():
+			branches = append(branches, name.Short())
+		case name.IsRemote():
+			// refs/remotes/<remote>/<branch>
+			parts := strings.SplitN(name.Short(), "/", 2)
+			if len(parts) == 2 && parts[1] != "HEAD" {
+				branches = append(branches, parts[1])
+			}
+		}
+		return nil
+	})
+	slices.Sort(branches)
+	return slices.Compact(branches)
+}
+
+// AddWorktree creates a worktree of the repository at path for branch
+//
+// The worktree is created next to the repository in a directory named
+// `<repo>@<branch>`. An existing local branch is checked out, a branch
+// that only exists on origin is created to track it and anything else
+// is created as a new branch from HEAD. The path of the new worktree
+// is returned
+func AddWorktree(path, branch string) (string, error) {
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return "", fmt.Errorf("a branch is needed to create a worktree")
+	}
+
+	dir := filepath.Base(path) + "@" + strings.ReplaceAll(branch, "/", "-")
+	worktree := filepath.Join(filepath.Dir(path), dir)
+	if _, err := os.Stat(worktree); err == nil {
+		return "", fmt.Errorf("%q already exists", worktree)
+	}
+
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"-C", path, "worktree", "add"}
+	switch {
+	case hasReference(repo, plumbing.NewBranchReferenceName(branch)):
+		args = append(args, worktree, branch)
+	case hasReference(repo, plumbing.NewRemoteReferenceName("origin", branch)):
+		args = append(args, "--track", "-b", branch, worktree, "origin/"+branch)
+	default:
+		args = append(args, "-b", branch, worktree)
+	}
+
+	if _, _, err := exec.Exec("git", args); err != nil {
+		return "", err
+	}
+	return worktree, nil
+}
+
+// RemoveWorktree removes the linked worktree at path when it is not
+// used by any tmux session other than the one being killed
+//
+// Paths that are not linked worktrees are left alone. git refuses
+// to remove a worktree with uncommitted changes and that error is
+// returned so nothing is lost
+func RemoveWorktree(path, killed string) error {
+	common, ok := worktreeOf(path)
+	if !ok {
+		return nil
+	}
+
+	for _, name := range tmux.SessionsAt(path) {
+		if name != killed {
+			return nil
+		}
+	}
+
+	_, _, err := exec.Exec("git", []string{
+		"-C", filepath.Dir(common), "worktree", "remove", path,
+	})
+	return err
+}
+
+// Find the git directory of the repository a linked worktree belongs to
+//
+// A linked worktree has a `.git` file pointing at its directory under
+// `.git/worktrees` in the main repository
+func worktreeOf(path string) (string, bool) {
+	content, err := os.ReadFile(filepath.Join(path, ".git"))
+	if err != nil {
+		return "", false
+	}
+	gitdir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir: ")
+	if !ok {
+		return "", false
+	}
+	if !filepath.IsAbs(gitdir) {
+		gitdir = filepath.Join(path, gitdir)
+	}
+	if filepath.Base(filepath.Dir(gitdir)) != "worktrees" {
+		// submodules also use a .git file
+		return "", false
+	}
+	return filepath.Dir(filepath.Dir(gitdir)), true
+}
+
+// Find the git directory shared by every worktree of the repository
+// at path
+//
+// In a linked worktree `.git` is a file naming its own git directory,
+// which in turn names the shared directory in `commondir`
+func commonDir(path string) string {
+	gitdir := filepath.Join(path, ".git")
+	if content, err := os.ReadFile(gitdir); err == nil {
+		if dir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir: "); ok {
+			gitdir = dir
+			if !filepath.IsAbs(gitdir) {
+				gitdir = filepath.Join(path, gitdir)
+			}
+		}
+	}
+	if content, err := os.ReadFile(filepath.Join(gitdir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(content))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitdir, common)
+		}
+		gitdir = filepath.Clean(common)
+	}
+	return gitdir
+}
+
+func hasReference(repo *git.Repository, name plumbing.ReferenceName) bool {
+	_, err := repo.Reference(name, false)
+	return err == nil
+}
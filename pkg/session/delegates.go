@@ -35,6 +35,7 @@ func (m *model) createListNormalDelegate() list.DefaultDelegate {
 		Foreground(theme.Colours.BrightBlue)
 	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
 		Foreground(theme.Colours.BrightWhite)
+	delegate.Styles.FilterMatch = delegate.Styles.FilterMatch.Bold(true)
 	return delegate
 }
 
@@ -48,5 +49,6 @@ func (m *model) createListShadedDelegate() list.DefaultDelegate {
 		Foreground(theme.Colours.BrightBlack)
 	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
 		Foreground(theme.Colours.BrightBlack)
+	delegate.Styles.FilterMatch = delegate.Styles.FilterMatch.Bold(true)
 	return delegate
 }
@@ -31,9 +31,13 @@ type keyMap struct {
 	CtrlS       key.Binding
 	Delete      key.Binding
 	Enter       key.Binding
+	Filter      key.Binding
+	Find        key.Binding
 	Help        key.Binding
 	HideContext key.Binding
+	Order       key.Binding
 	Quit        key.Binding
+	Resources   key.Binding
 	ShiftTab    key.Binding
 	Tab         key.Binding
 	ToggleZoom  key.Binding
@@ -52,10 +56,10 @@ func (k *keyMap) ShortHelp() []key.Binding {
 func (k *keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{
-			k.CtrlN, k.CtrlS, k.Delete, k.Enter, k.Help, k.HideContext, k.SessionMode,
+			k.CtrlN, k.CtrlS, k.Delete, k.Enter, k.Filter, k.Find, k.Help, k.HideContext, k.Order, k.SessionMode,
 		},
 		{
-			k.Quit, k.ShiftTab, k.Tab, k.ToggleZoom, k.WindowMode, k.Rename, k.SplitHorizontal, k.SplitVertical,
+			k.Quit, k.Resources, k.ShiftTab, k.Tab, k.ToggleZoom, k.WindowMode, k.Rename, k.SplitHorizontal, k.SplitVertical,
 		},
 	}
 }
@@ -70,13 +74,21 @@ func mapKeys() *keyMap {
 			key.WithHelp("del/x", "Delete current item")),
 		Enter: key.NewBinding(key.WithKeys("enter"),
 			key.WithHelp(icons.Enter, "Select current item")),
+		Filter: key.NewBinding(key.WithKeys("/"),
+			key.WithHelp("/", "Filter sessions or windows")),
+		Find: key.NewBinding(key.WithKeys("ctrl+f"),
+			key.WithHelp("ctrl+f", "Find context in any session")),
 		Help: key.NewBinding(key.WithKeys("?", "f1"),
 			key.WithHelp("?", "Help")),
 
 		HideContext: key.NewBinding(key.WithKeys("K"),
 			key.WithHelp("K", "Hide context pane")),
+		Order: key.NewBinding(key.WithKeys("o"),
+			key.WithHelp("o", "Sort by frecency, name or age")),
 		Quit: key.NewBinding(key.WithKeys("ctrl+c", "esc"),
 			key.WithHelp("esc", "Close overlays or Quit")),
+		Resources: key.NewBinding(key.WithKeys("P"),
+			key.WithHelp("P", "Show pods and workloads")),
 		Rename: key.NewBinding(key.WithKeys("r"),
 			key.WithHelp("r", "rename window/session")),
 		SessionMode: key.NewBinding(key.WithKeys("s"),
@@ -27,25 +27,30 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mproffitt/bmx/pkg/components/createpanel"
+	"github.com/mproffitt/bmx/pkg/components/matcher"
 	"github.com/mproffitt/bmx/pkg/components/splash"
 	"github.com/mproffitt/bmx/pkg/components/viewport"
 	"github.com/mproffitt/bmx/pkg/config"
 	"github.com/mproffitt/bmx/pkg/helpers"
 	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/kubernetes/ui/where"
+	"github.com/mproffitt/bmx/pkg/providers"
+	"github.com/mproffitt/bmx/pkg/providers/ui/tabs"
 	"github.com/mproffitt/bmx/pkg/repos"
 	"github.com/mproffitt/bmx/pkg/repos/ui/table"
 	"github.com/mproffitt/bmx/pkg/theme"
-	"github.com/mproffitt/bmx/pkg/tmux"
 	"github.com/mproffitt/bmx/pkg/tmux/ui/manager"
 )
 
 func New(c *config.Config) *model {
 	manager, Iterator := manager.New()
 	items := []list.Item{}
+	enabled := len(providers.Enabled(c)) > 0
 	m := model{
 		active:          sessionManager,
 		config:          c,
-		contextHidden:   !c.ManageSessionKubeContext,
+		contextEnabled:  enabled,
+		contextHidden:   !enabled,
 		focused:         sessionList,
 		keymap:          mapKeys(),
 		list:            list.New(items, list.NewDefaultDelegate(), 0, 0),
@@ -74,7 +79,10 @@ func New(c *config.Config) *model {
 
 	m.list.SetDelegate(m.styles.delegates.normal)
 	m.list.SetShowFilter(false)
-	m.list.SetFilteringEnabled(false)
+	m.list.SetFilteringEnabled(true)
+	m.list.Filter = matcher.Filter
+	m.list.KeyMap.Filter = m.keymap.Filter
+	m.list.KeyMap.NextPage.SetKeys("right", "l", "pgdown", "d")
 	m.list.SetShowHelp(false)
 	m.list.SetShowPagination(true)
 	m.list.SetShowStatusBar(false)
@@ -85,12 +93,24 @@ func New(c *config.Config) *model {
 }
 
 func (m *model) Init() tea.Cmd {
-	return tea.Batch(m.splash.Init(), m.manager.Init())
+	cmds := []tea.Cmd{m.splash.Init(), m.manager.Init()}
+	if m.config.ManageSessionKubeContext {
+		// The context pane picks up the kubeconfig to watch once created
+		cmds = append(cmds, kubernetes.WatchCmd(""))
+	}
+	if m.contextEnabled {
+		// every provider is watched for changes of its own
+		cmds = append(cmds, tabs.WatchCmd(m.config))
+	}
+	return tea.Batch(cmds...)
 }
 
 // Overlay is used to present the overlay for creating new sessions
 // or windows depending on which list is currently visible
 func (m *model) Overlay() helpers.UseOverlay {
+	if m.searching {
+		return where.New()
+	}
 	width := int(math.Ceil(float64(m.width) * .8))
 	height := int(math.Ceil(float64(m.height) * .75))
 	switch m.active {
@@ -111,14 +131,6 @@ func (m *model) GetSize() (int, int) {
 	return m.width, m.height
 }
 
-func (m *model) getSessionKubeconfig(session string) string {
-	kubeconfig := tmux.GetTmuxEnvVar(session, "KUBECONFIG")
-	if kubeconfig == "" {
-		kubeconfig = kubernetes.DefaultConfigFile()
-	}
-	return kubeconfig
-}
-
 func (m *model) Ready() bool {
 	return m.manager.Ready && m.ready
 }
@@ -33,7 +33,9 @@ func (m *model) setItems() {
 	}
 }
 
+// Replacing the items clears any filter on the list
 func (m *model) setSessionItems() {
+	m.list.ResetFilter()
 	sessions := m.manager.Items()
 	items := make([]list.Item, len(sessions))
 	for i, s := range sessions {
@@ -52,6 +54,7 @@ func (m *model) setSessionItems() {
 }
 
 func (m *model) setWindowsItems(session string) {
+	m.list.ResetFilter()
 	windows := tmuxui.ListWindows(session)
 	items := make([]list.Item, len(windows))
 	for i, w := range windows {
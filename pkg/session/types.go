@@ -29,6 +29,7 @@ import (
 	"github.com/mproffitt/bmx/pkg/components/toast"
 	"github.com/mproffitt/bmx/pkg/components/viewport"
 	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/kubernetes/ui/resources"
 	"github.com/mproffitt/bmx/pkg/tmux/ui/manager"
 	"github.com/mproffitt/bmx/pkg/tmux/ui/session"
 	tmuxui "github.com/mproffitt/bmx/pkg/tmux/ui/window"
@@ -52,6 +53,7 @@ const (
 	sessionList overlay.FocusType = iota
 	previewPane
 	contextPane
+	resourcePane
 	overlayPane
 	renamePane
 	dialogp
@@ -70,10 +72,16 @@ type model struct {
 
 	// This is for the context pane
 	// can I collapse these into the same unit?
-	context       tea.Model
-	contextHidden bool
+	context        tea.Model
+	contextEnabled bool // any context provider is enabled
+	contextHidden  bool
 
-	deleting bool
+	deleting  bool
+	searching bool
+
+	// Optional view of pods and workloads below the preview
+	resources      *resources.Model
+	resourcesShown bool
 
 	dialog tea.Model
 
@@ -21,6 +21,7 @@ package session
 
 import (
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mproffitt/bmx/pkg/components/createpanel"
@@ -29,6 +30,9 @@ import (
 	"github.com/mproffitt/bmx/pkg/config"
 	"github.com/mproffitt/bmx/pkg/helpers"
 	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/kubernetes/ui/where"
+	"github.com/mproffitt/bmx/pkg/providers"
+	"github.com/mproffitt/bmx/pkg/repos"
 	"github.com/mproffitt/bmx/pkg/tmux/ui/manager"
 	"github.com/mproffitt/bmx/pkg/tmux/ui/session"
 	tmuxui "github.com/mproffitt/bmx/pkg/tmux/ui/window"
@@ -51,7 +55,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if selected, ok := m.list.SelectedItem().(*session.Session); ok {
 			m.session = selected
 		}
-		if m.session != nil {
+		// the index is into the full list so is only
+		// selected while the list is unfiltered
+		if m.session != nil && m.list.FilterState() == list.Unfiltered {
 			m.list.Select(int((*m.session).Index))
 		}
 	case windowManager:
@@ -80,6 +86,15 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
+		// A filter being typed takes every key
+		if m.focused == sessionList && m.list.FilterState() != list.Unfiltered {
+			var done bool
+			if cmd, done = m.filterKeyMessage(msg); done {
+				return m, cmd
+			}
+			cmds = append(cmds, cmd)
+		}
+
 		// Main window key handling
 		var early bool
 		cmd, early, err = m.switchKeyMessage(msg, &sendOverlayUpdate)
@@ -92,6 +107,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch m.focused {
 		case sessionList:
 			m.list, cmd = m.list.Update(msg)
+			m.list.SetShowFilter(m.list.FilterState() == list.Filtering)
 			m.list.SetDelegate(m.styles.delegates.normal)
 			cmds = append(cmds, cmd)
 			if m.dialog == nil && key.Matches(msg, m.keymap.Enter) {
@@ -121,10 +137,47 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.overlay.Model = model.(helpers.UseOverlay)
 			cmds = append(cmds, cmd)
 		}
+		cmds = append(cmds, m.watchResources(false))
 
-	case kubernetes.ContextDeleteMsg, kubernetes.ContextChangeMsg:
+	case kubernetes.ContextDeleteMsg, kubernetes.ContextChangeMsg, kubernetes.NamespacesMsg:
 		m.context, cmd = m.context.Update(msg)
 		cmds = append(cmds, cmd)
+	case kubernetes.ResourcesMsg:
+		if m.resources != nil {
+			_, cmd = m.resources.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+	case repos.IndexMsg, repos.SourcesMsg, repos.StatusMsg, repos.CloneMsg, repos.PreviewMsg:
+		// the create session table streams repositories, extra
+		// directories, their git status, previews and clone
+		// progress in while it is open
+		if m.overlay != nil {
+			var model tea.Model
+			model, cmd = m.overlay.Model.Update(msg)
+			m.overlay.Model = model.(helpers.UseOverlay)
+			cmds = append(cmds, cmd)
+		}
+	case where.JumpMsg:
+		err = m.jump(msg)
+		cmds = append(cmds, m.watchResources(false))
+	case kubernetes.WatchMsg:
+		if m.context == nil {
+			cmds = append(cmds, kubernetes.WatchCmd(msg.Kubeconfig))
+		} else {
+			m.context, cmd = m.context.Update(msg)
+			cmds = append(cmds, cmd)
+		}
+		// pick up changes to the session context or namespace
+		if m.resources != nil && m.resources.Changed(msg) {
+			cmds = append(cmds, m.watchResources(true))
+		}
+	case providers.WatchMsg:
+		if m.context == nil {
+			cmds = append(cmds, providers.WatchCmd(providers.Get(msg.Provider), msg.Session))
+		} else {
+			m.context, cmd = m.context.Update(msg)
+			cmds = append(cmds, cmd)
+		}
 	case helpers.OverlayMsg:
 		if m.overlay != nil {
 			_, cmd = (*m.overlay.Parent).Update(msg)
@@ -181,6 +234,9 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.overlay.Model = model.(helpers.UseOverlay)
 			cmds = append(cmds, cmd)
 		}
+	case list.FilterMatchesMsg:
+		m.list, cmd = m.list.Update(msg)
+		cmds = append(cmds, cmd, m.watchResources(false))
 	case manager.ManagerReadyMsg:
 		if msg.Ready {
 			cmds = append(cmds, helpers.ReloadManagerCmd())
@@ -196,6 +252,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.list.Index() >= len(m.list.Items()) {
 			m.list.Select(0)
 		}
+		cmds = append(cmds, m.watchResources(false))
 	case helpers.ErrorMsg:
 		if m.focused == overlayPane {
 			m.focused = m.overlay.Previous
@@ -28,19 +28,20 @@ import (
 	"github.com/mproffitt/bmx/pkg/components/dialog"
 	"github.com/mproffitt/bmx/pkg/components/overlay"
 	"github.com/mproffitt/bmx/pkg/config"
+	"github.com/mproffitt/bmx/pkg/providers/ui/tabs"
 	"github.com/mproffitt/bmx/pkg/theme"
 	"github.com/muesli/reflow/wordwrap"
 )
 
 func (m *model) delete(msg tea.Msg) tea.Cmd {
-	if m.focused == overlayPane {
+	if m.focused == overlayPane || m.focused == resourcePane {
 		return nil
 	}
 
 	var cmd tea.Cmd
 	if m.focused == contextPane {
 		m.context, cmd = m.context.Update(msg)
-		if m.overlay == nil {
+		if m.overlay == nil && m.context.(*tabs.Model).RequiresOverlay() {
 			m.overlay = overlay.New(&m.context, m.focused)
 			m.focused = overlayPane
 		}
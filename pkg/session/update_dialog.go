@@ -27,6 +27,8 @@ import (
 	"github.com/mproffitt/bmx/pkg/components/toast"
 	"github.com/mproffitt/bmx/pkg/helpers"
 	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/repos"
+	"github.com/mproffitt/bmx/pkg/tmux"
 )
 
 func (m *model) handleDialog(status dialog.Status) (tea.Cmd, error) {
@@ -41,10 +43,21 @@ func (m *model) handleDialog(status dialog.Status) (tea.Cmd, error) {
 		switch m.active {
 		case sessionManager:
 			if m.deleting {
-				err = kubernetes.DeleteConfig(m.session.Name)
+				// in the same order as `bmx kill` so a worktree
+				// git refuses to remove leaves the session intact
+				if m.config.RemoveWorktrees {
+					err = repos.RemoveWorktree(tmux.SessionPath(m.session.Name), m.session.Name)
+				}
+				if err == nil {
+					err = kubernetes.DeleteConfig(m.session.Name)
+				}
 				if err == nil {
 					err = m.manager.KillSwitch(m.session.Name, m.config.DefaultSession)
 				}
+				if err != nil {
+					err = fmt.Errorf("failed to delete session %q %w", m.session.Name, err)
+					break
+				}
 				cmds = append(cmds, toast.NewToastCmd(toast.Info, "Deleted session "+m.session.Name))
 			}
 		case windowManager:
@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package session
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Send keys to the list while a filter is being typed
+//
+// Enter accepts the filter and carries on so the best match is
+// opened straight away. Esc clears a filter that has been accepted
+func (m *model) filterKeyMessage(msg tea.KeyMsg) (cmd tea.Cmd, done bool) {
+	switch m.list.FilterState() {
+	case list.Filtering:
+		m.list, cmd = m.list.Update(msg)
+		m.list.SetShowFilter(m.list.FilterState() == list.Filtering)
+		return cmd, !key.Matches(msg, m.keymap.Enter)
+	case list.FilterApplied:
+		if msg.String() == "esc" {
+			m.list.ResetFilter()
+			return m.watchResources(false), true
+		}
+	}
+	return nil, false
+}
@@ -26,9 +26,12 @@ import (
 func (m *model) displayHelp() {
 	entries := make([]dialog.HelpEntry, 0)
 	entries = append(entries, m.Help())
-	if m.config.ManageSessionKubeContext && m.context != nil {
+	if m.contextEnabled && m.context != nil {
 		entries = append(entries, m.context.(dialog.UseHelp).Help())
 	}
+	if m.resourcesShown {
+		entries = append(entries, m.resources.Help())
+	}
 
 	m.dialog = dialog.HelpDialog(entries...)
 }
@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package session
+
+import (
+	"fmt"
+
+	"github.com/mproffitt/bmx/pkg/kubernetes/ui/where"
+	"github.com/mproffitt/bmx/pkg/providers"
+	"github.com/mproffitt/bmx/pkg/providers/ui/tabs"
+)
+
+// Jump to the session holding a context found by the context search
+//
+// The session is selected in the session list, clearing any filter,
+// and, if the context pane is enabled, it is focused with the context
+// selected
+func (m *model) jump(msg where.JumpMsg) error {
+	if m.overlay != nil {
+		m.focused = m.overlay.Previous
+		m.overlay = nil
+	}
+	if m.active == windowManager {
+		m.setSessionItems()
+		m.active = sessionManager
+	}
+
+	target := m.manager.Session(msg.Session)
+	if target == nil {
+		return fmt.Errorf("session %q is not running", msg.Session)
+	}
+	// the index is into the full list and the session may
+	// be hidden by the filter
+	m.session = target
+	m.list.ResetFilter()
+	m.list.SetShowFilter(false)
+	m.list.Select(int(target.Index))
+
+	if !m.config.ManageSessionKubeContext {
+		m.focused = sessionList
+		return nil
+	}
+	if m.contextHidden {
+		m.contextHidden = false
+		m.resize()
+	}
+	if m.context == nil {
+		m.focused = sessionList
+		return nil
+	}
+
+	m.context = m.context.(*tabs.Model).SetSession(target.Name)
+	m.context = m.context.(*tabs.Model).SelectContext(providers.Kubernetes, msg.Context)
+	m.context = m.context.(*tabs.Model).Focus()
+	m.focused = contextPane
+	return nil
+}
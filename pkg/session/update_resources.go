@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Martin Proffitt <mprooffitt@choclab.net>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package session
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mproffitt/bmx/pkg/kubernetes/ui/resources"
+	"github.com/mproffitt/bmx/pkg/providers"
+	"github.com/mproffitt/bmx/pkg/tmux/ui/session"
+)
+
+// Show or hide the resource view below the preview
+//
+// Watches are stopped while the view is hidden
+func (m *model) toggleResources() tea.Cmd {
+	m.resourcesShown = !m.resourcesShown
+	if m.resources == nil {
+		m.resources = resources.New()
+	}
+	if !m.resourcesShown {
+		m.resources.Stop()
+		if m.focused == resourcePane {
+			m.focused = sessionList
+		}
+	}
+	m.resize()
+	return m.watchResources(true)
+}
+
+// Point the resource view at the selected session
+//
+// This is called when the selection may have changed and, unless
+// forced, only does anything when a different session has been
+// selected. Forcing it also checks if the context or namespace of
+// the session has changed
+func (m *model) watchResources(force bool) tea.Cmd {
+	if !m.resourcesShown {
+		return nil
+	}
+	selected := m.session
+	if s, ok := m.list.SelectedItem().(*session.Session); ok && m.active == sessionManager {
+		selected = s
+	}
+	if selected == nil {
+		return nil
+	}
+	if !force && m.resources.Session() == selected.Name {
+		return nil
+	}
+	return m.resources.SetTarget(selected.Name, selected.Path,
+		providers.Kubeconfig(selected.Name))
+}
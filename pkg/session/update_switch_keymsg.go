@@ -24,8 +24,9 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/log"
 	"github.com/mproffitt/bmx/pkg/components/overlay"
+	"github.com/mproffitt/bmx/pkg/components/toast"
 	"github.com/mproffitt/bmx/pkg/helpers"
-	"github.com/mproffitt/bmx/pkg/kubernetes/ui/panel"
+	"github.com/mproffitt/bmx/pkg/providers/ui/tabs"
 )
 
 func (m *model) switchKeyMessage(msg tea.KeyMsg, sendOverlayUpdate *bool) (cmd tea.Cmd, returnEarly bool, err error) {
@@ -75,28 +76,46 @@ func (m *model) switchKeyMessage(msg tea.KeyMsg, sendOverlayUpdate *bool) (cmd t
 		case sessionList:
 			m.focused = previewPane
 		case previewPane:
+			if m.resourcesShown {
+				m.focused = resourcePane
+				break
+			}
+			m.focused = sessionList
+			if m.contextEnabled {
+				m.focused = contextPane
+				m.context = m.context.(*tabs.Model).Focus()
+			}
+		case resourcePane:
 			m.focused = sessionList
-			if m.config.ManageSessionKubeContext {
+			if m.contextEnabled {
 				m.focused = contextPane
-				m.context = m.context.(*panel.Model).Focus()
+				m.context = m.context.(*tabs.Model).Focus()
 			}
 		case contextPane:
 			m.focused = sessionList
-			m.context = m.context.(*panel.Model).Blur()
+			m.context = m.context.(*tabs.Model).Blur()
 		}
 	case key.Matches(msg, m.keymap.ShiftTab):
 		switch m.focused {
 		case sessionList:
 			m.focused = previewPane
-			if m.config.ManageSessionKubeContext {
+			if m.resourcesShown {
+				m.focused = resourcePane
+			}
+			if m.contextEnabled {
 				m.focused = contextPane
-				m.context = m.context.(*panel.Model).Focus()
+				m.context = m.context.(*tabs.Model).Focus()
 			}
 		case previewPane:
 			m.focused = sessionList
+		case resourcePane:
+			m.focused = previewPane
 		case contextPane:
 			m.focused = previewPane
-			m.context = m.context.(*panel.Model).Blur()
+			if m.resourcesShown {
+				m.focused = resourcePane
+			}
+			m.context = m.context.(*tabs.Model).Blur()
 		}
 	case key.Matches(msg, m.keymap.CtrlN):
 		// Create a New session by launching the create session
@@ -106,6 +125,17 @@ func (m *model) switchKeyMessage(msg tea.KeyMsg, sendOverlayUpdate *bool) (cmd t
 		cmd = m.overlay.Model.(tea.Model).Init()
 		cmds = append(cmds, cmd)
 		m.focused = overlayPane
+	case key.Matches(msg, m.keymap.Find):
+		// Search the kubeconfig of every session in an overlay.
+		// Return early so the key isn't typed into the search
+		if m.focused != overlayPane {
+			m.searching = true
+			model := tea.Model(m)
+			m.overlay = overlay.New(&model, m.focused)
+			m.searching = false
+			m.focused = overlayPane
+			returnEarly = true
+		}
 	case key.Matches(msg, m.keymap.CtrlS):
 		cmd = helpers.SaveSessionsCmd()
 		cmds = append(cmds, cmd)
@@ -129,6 +159,10 @@ func (m *model) switchKeyMessage(msg tea.KeyMsg, sendOverlayUpdate *bool) (cmd t
 			m.contextHidden = !m.contextHidden
 			m.resize()
 		}
+	case key.Matches(msg, m.keymap.Resources):
+		if m.focused != overlayPane {
+			cmds = append(cmds, m.toggleResources())
+		}
 	case key.Matches(msg, m.keymap.SessionMode):
 		if m.focused != overlayPane {
 			switch m.active {
@@ -150,6 +184,12 @@ func (m *model) switchKeyMessage(msg tea.KeyMsg, sendOverlayUpdate *bool) (cmd t
 			}
 			returnEarly = true
 		}
+	case key.Matches(msg, m.keymap.Order):
+		if m.focused == sessionList && m.active == sessionManager {
+			m.manager.SetOrder(m.manager.Order().Toggle())
+			m.setSessionItems()
+			cmds = append(cmds, toast.NewToastCmd(toast.Info, "Sessions sorted by "+m.manager.Order().String()))
+		}
 	case key.Matches(msg, m.keymap.Rename):
 		if m.focused == sessionList {
 			m.rename()
@@ -160,12 +200,15 @@ func (m *model) switchKeyMessage(msg tea.KeyMsg, sendOverlayUpdate *bool) (cmd t
 		case contextPane:
 			m.context, cmd = m.context.Update(msg)
 			cmds = append(cmds, cmd)
-			if m.overlay == nil && m.context.(*panel.Model).RequiresOverlay() {
+			if m.overlay == nil && m.context.(*tabs.Model).RequiresOverlay() {
 				// don't send an update to the overlay on first creation
 				*sendOverlayUpdate = false
 				m.overlay = overlay.New(&m.context, m.focused)
 				m.focused = overlayPane
 			}
+		case resourcePane:
+			_, cmd = m.resources.Update(msg)
+			cmds = append(cmds, cmd)
 		case previewPane:
 			// TODO: This is currently only used for zooming the given
 			// pane as part of the preview window.
@@ -28,7 +28,7 @@ import (
 	"github.com/mproffitt/bmx/pkg/components/overlay"
 	"github.com/mproffitt/bmx/pkg/components/viewport"
 	"github.com/mproffitt/bmx/pkg/config"
-	"github.com/mproffitt/bmx/pkg/kubernetes/ui/panel"
+	"github.com/mproffitt/bmx/pkg/providers/ui/tabs"
 	"github.com/mproffitt/bmx/pkg/tmux/ui/session"
 	tmuxui "github.com/mproffitt/bmx/pkg/tmux/ui/window"
 )
@@ -57,9 +57,8 @@ func (m *model) View() string {
 		return splash
 	}
 
-	if m.config.ManageSessionKubeContext && m.context != nil {
-		m.context = m.context.(*panel.Model).UpdateContextList(
-			m.session.Name, m.getSessionKubeconfig(m.session.Name))
+	if m.contextEnabled && m.context != nil {
+		m.context = m.context.(*tabs.Model).SetSession(m.session.Name)
 	}
 
 	var left string
@@ -94,7 +93,15 @@ func (m *model) View() string {
 		}
 
 		right.WriteString(m.preview.View())
-		if m.config.ManageSessionKubeContext && m.context != nil && !m.contextHidden {
+		if m.resourcesShown {
+			if m.focused == resourcePane {
+				m.resources.Focus()
+			} else {
+				m.resources.Blur()
+			}
+			right.WriteString("\n" + m.resources.View())
+		}
+		if m.contextEnabled && m.context != nil && !m.contextHidden {
 			right.WriteString("\n" + m.context.View())
 		}
 	}
@@ -24,6 +24,8 @@ import (
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/mproffitt/bmx/pkg/kubernetes/ui/panel"
+	"github.com/mproffitt/bmx/pkg/kubernetes/ui/resources"
+	"github.com/mproffitt/bmx/pkg/providers/ui/tabs"
 )
 
 func (m *model) resize() {
@@ -37,7 +39,14 @@ func (m *model) resize() {
 	m.list.SetSize(width, height-padding)
 	m.preview.SetSize(previewWidth, height)
 
-	if m.config.ManageSessionKubeContext && !m.contextHidden {
+	// the resource view takes its space from the preview
+	if m.resourcesShown {
+		height -= resources.Height
+		m.preview.SetSize(previewWidth, height)
+		m.resources.SetSize(previewWidth-1, resources.Height)
+	}
+
+	if m.contextEnabled && !m.contextHidden {
 		// look for 40% of the screen space
 		sessionHeight := int(math.Ceil(float64(height) * kubernetesSessionHeight))
 
@@ -58,9 +67,9 @@ func (m *model) resize() {
 
 		if m.context == nil {
 			session := m.list.SelectedItem().(list.DefaultItem).Title()
-			m.context = panel.NewKubectxPane(m.config, session, rows, cols, colWidth)
+			m.context = tabs.New(m.config, session, rows, cols, colWidth)
 		}
 		m.preview.SetSize(previewWidth, (height-sessionHeight)-2)
-		m.context = m.context.(*panel.Model).SetSize(previewWidth-6, sessionHeight, colWidth)
+		m.context = m.context.(*tabs.Model).SetSize(previewWidth-6, sessionHeight, colWidth)
 	}
 }
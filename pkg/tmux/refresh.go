@@ -74,6 +74,18 @@ func SetSessionEnvironment(session, variable, value string) error {
 	return nil
 }
 
+// UnsetSessionEnvironment removes a value from the TMUX session environment
+func UnsetSessionEnvironment(session, variable string) error {
+	args := []string{
+		"set-environment", "-u", "-t", session, variable,
+	}
+	_, e, err := Exec(args)
+	if err != nil {
+		return fmt.Errorf("failed to unset %q environment variable for session %q %q %w", variable, session, e, err)
+	}
+	return nil
+}
+
 // Send tmux environment vars to all running panes
 //
 // This function uses the send-keys functionality to attempt
@@ -24,6 +24,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/mproffitt/bmx/pkg/frecency"
 	"github.com/mproffitt/bmx/pkg/kubernetes"
 )
 
@@ -42,6 +43,8 @@ func AttachSession(name string) error {
 			return err
 		}
 	}
+	// a failure to record the visit only affects ordering
+	_ = frecency.Visit(frecency.Sessions, name)
 	return nil
 }
 
@@ -53,7 +56,7 @@ func CreateSession(name, path, command string, includeKubeConfig, attach bool) e
 	}
 
 	if includeKubeConfig {
-		if config, err := kubernetes.CreateConfig(name); err == nil {
+		if config, err := kubernetes.SessionKubeconfig(name, kubernetes.DefaultLayers()); err == nil {
 			kubeConfig := fmt.Sprintf("KUBECONFIG=%s", config)
 			args = append(args, "-e", kubeConfig)
 		}
@@ -75,11 +78,13 @@ func CreateSession(name, path, command string, includeKubeConfig, attach bool) e
 }
 
 // Get the name of the current session
+//
+// An empty name is returned when not running inside tmux
 func CurrentSession() string {
 	name, _, err := Exec([]string{
 		"display-message", "-p", "#{session_name}",
 	})
-	if err == nil {
+	if err != nil {
 		return ""
 	}
 	return name
@@ -124,6 +129,18 @@ func ListSessions() []string {
 	return strings.Split(sessions, "\n")
 }
 
+// Get the names of the sessions started in the given path
+func SessionsAt(path string) []string {
+	names := make([]string, 0)
+	for _, session := range ListSessions() {
+		fields := strings.Split(session, ",")
+		if len(fields) > 1 && fields[len(fields)-1] == path {
+			names = append(names, fields[0])
+		}
+	}
+	return names
+}
+
 // Creates a new session and attaches to it.
 // If the session already exists, it is simply attached.
 func NewSessionOrAttach(in map[string]any, includeKubeConfig bool) error {
@@ -172,10 +189,41 @@ func NewSessionOrAttach(in map[string]any, includeKubeConfig bool) error {
 }
 
 // Rename a tmux session
+//
+// The kubeconfig file owned by the session is moved to the new name
+// so it is still found by name, and the session environment updated
+// to match. Failing to move it leaves the session using the old file
+//
+// A name still held by the kubeconfig of another renamed session
+// cannot be used
 func RenameSession(target, name string) error {
-	return ExecSilent([]string{
+	if kubernetes.RenamedConfigHeld(name, target) {
+		return fmt.Errorf("session name %q is still used by the kubeconfig of a renamed session", name)
+	}
+	err := ExecSilent([]string{
 		"rename-session", "-t", target, name,
 	})
+	if err != nil {
+		return err
+	}
+	_ = frecency.Rename(frecency.Sessions, target, name)
+
+	kubeconfig := GetTmuxEnvVar(name, "KUBECONFIG")
+	if kubernetes.WritableConfig(kubeconfig) != kubernetes.SessionConfigFile(target) {
+		return nil
+	}
+	file, linkErr := kubernetes.RenameConfig(target, name)
+	if file == "" {
+		return nil
+	}
+	files := kubernetes.ConfigFiles(kubeconfig)
+	files[0] = file
+	if err := SetSessionEnvironment(name, "KUBECONFIG", kubernetes.JoinConfigFiles(files...)); err != nil {
+		return err
+	}
+
+	// shells in the session only keep working through the link
+	return linkErr
 }
 
 // List all panes in a given session
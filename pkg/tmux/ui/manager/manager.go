@@ -25,6 +25,7 @@ import (
 	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mproffitt/bmx/pkg/frecency"
 	"github.com/mproffitt/bmx/pkg/kubernetes"
 	"github.com/mproffitt/bmx/pkg/tmux"
 	"github.com/mproffitt/bmx/pkg/tmux/ui/session"
@@ -50,8 +51,34 @@ const (
 	NameReverse
 	Oldest
 	Newest
+	Frecency
 )
 
+// Toggle steps through the orders offered in the pickers,
+// frecency then name then age
+func (s SortBy) Toggle() SortBy {
+	switch s {
+	case Frecency:
+		return Name
+	case Name:
+		return Oldest
+	}
+	return Frecency
+}
+
+// How the order is described to the user
+func (s SortBy) String() string {
+	switch s {
+	case NameReverse:
+		return "name reversed"
+	case Oldest, Newest:
+		return "age"
+	case Frecency:
+		return "frecency"
+	}
+	return "name"
+}
+
 const (
 	First GetBy = iota
 	Last
@@ -61,6 +88,7 @@ type Model struct {
 	sync.Mutex
 	sessions  []*session.Session
 	baseIndex uint
+	order     SortBy
 	Ready     bool
 }
 
@@ -71,6 +99,7 @@ func New() (*Model, Iterator) {
 	baseIndex := tmux.GetBaseIndex()
 	m := Model{
 		baseIndex: baseIndex,
+		order:     Frecency,
 	}
 
 	return &m, func(yield func(key int, val *session.Session) bool) {
@@ -115,8 +144,13 @@ func (m *Model) Has(name string) bool {
 	return false
 }
 
-// Get the session items
+// Get the session items in the current order
+//
+// Ordering by name keeps attached sessions at the top
 func (m *Model) Items() []*session.Session {
+	if m.order != Name {
+		return m.Sort(m.order)
+	}
 	sort.SliceStable(m.sessions, func(i, j int) bool {
 		if m.sessions[i].Attached != m.sessions[j].Attached {
 			return m.sessions[i].Attached
@@ -187,10 +221,29 @@ func (m *Model) Refresh(includeKubeconfig, sendVars bool) error {
 	return nil
 }
 
+// The order Items are returned in
+func (m *Model) Order() SortBy {
+	return m.order
+}
+
+// Set the order Items are returned in
+func (m *Model) SetOrder(by SortBy) {
+	m.order = by
+}
+
 // List all tmux sessions and sort them by the order provided
 func (m *Model) Sort(by SortBy) []*session.Session {
+	var scores map[string]float64
+	if by == Frecency {
+		scores = frecency.Scores(frecency.Sessions)
+	}
 	sort.SliceStable(m.sessions, func(i, j int) bool {
 		switch by {
+		case Frecency:
+			a, b := scores[m.sessions[i].Name], scores[m.sessions[j].Name]
+			if a != b {
+				return a > b
+			}
 		case Name: // default behaviour
 		case NameReverse:
 			return m.sessions[j].Name < m.sessions[i].Name
@@ -205,9 +258,15 @@ func (m *Model) Sort(by SortBy) []*session.Session {
 }
 
 // Send an update to TMUX for the KUBECONFIG session name
+//
+// Any shared layers a session already uses are kept
 func (m *Model) UpdateEnvironment() error {
 	for _, session := range m.sessions {
-		configFile, err := kubernetes.CreateConfig(session.Name)
+		layers := kubernetes.ConfigLayers(tmux.GetTmuxEnvVar(session.Name, "KUBECONFIG"))
+		if layers == nil {
+			layers = kubernetes.DefaultLayers()
+		}
+		configFile, err := kubernetes.SessionKubeconfig(session.Name, layers)
 		if err != nil {
 			return fmt.Errorf("failed to create kubeconfig for session %q %w", session.Name, err)
 		}
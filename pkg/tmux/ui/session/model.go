@@ -27,8 +27,12 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mproffitt/bmx/pkg/components/createpanel"
+	"github.com/mproffitt/bmx/pkg/components/matcher"
 	"github.com/mproffitt/bmx/pkg/components/toast"
 	"github.com/mproffitt/bmx/pkg/helpers"
+	"github.com/mproffitt/bmx/pkg/kubernetes"
+	"github.com/mproffitt/bmx/pkg/portforward"
+	"github.com/mproffitt/bmx/pkg/providers"
 	"github.com/mproffitt/bmx/pkg/tmux"
 	"github.com/mproffitt/bmx/pkg/tmux/ui/window"
 )
@@ -40,6 +44,7 @@ type Renamable interface {
 
 type Session struct {
 	Attached   bool
+	AWSProfile string
 	Created    time.Time
 	Index      uint
 	Group      string // Future
@@ -72,6 +77,7 @@ func New(session string) *Session {
 		s.Created = time.Unix(t, 0)
 	}
 	s.Windows = window.ListWindows(s.Name)
+	s.AWSProfile = tmux.GetTmuxEnvVar(s.Name, "AWS_PROFILE")
 	return &s
 }
 
@@ -90,16 +96,25 @@ func (s *Session) CreateWindow(msg createpanel.ObserverMsg) tea.Cmd {
 }
 
 // Get the description of this session
+//
+// The AWS profile of the session leads the first line when one is set
 func (s *Session) Description() string {
 	date := s.Created.Format(time.ANSIC)
+	status := date
+	if s.Attached {
+		status = "active"
+	}
+	if s.AWSProfile != "" {
+		status = "aws:" + s.AWSProfile + " " + status
+	}
 	if s.Attached {
-		return fmt.Sprintf("active\n%s", date)
+		return fmt.Sprintf("%s\n%s", status, date)
 	}
-	return date
+	return status
 }
 
 // Filter value for filterable lists
-func (s *Session) FilterValue() string { return s.Name }
+func (s *Session) FilterValue() string { return matcher.Join(s.Name, s.Path) }
 
 // Gets the name of the current session
 func (s *Session) GetName() string { return s.Name }
@@ -111,17 +126,32 @@ func (s *Session) KillWindow(index uint64) error {
 }
 
 // Rename session
+//
+// Port-forward supervisors follow the session by name so the old one
+// is stopped, releasing its ports, before one is started for the new name
 func (s *Session) Rename(name string) error {
-	return tmux.RenameSession(s.Name, name)
+	if err := tmux.RenameSession(s.Name, name); err != nil {
+		return err
+	}
+	if err := portforward.Stop(s.Name); err != nil {
+		return err
+	}
+	if forwards, err := portforward.Load(name); err == nil && len(forwards) > 0 {
+		return portforward.Start(name)
+	}
+	return nil
 }
 
 // Marshal an individual session
 func (s *Session) ToHelperStruct() helpers.Session {
 	session := helpers.Session{
-		Name:    s.Name,
-		Command: s.command,
-		Path:    s.Path,
-		Windows: make([]helpers.Window, 0),
+		Name:        s.Name,
+		Command:     s.command,
+		Environment: providers.SessionEnvironment(s.Name),
+		Forwards:    s.forwards(),
+		KubeLayers:  kubernetes.ConfigLayers(tmux.GetTmuxEnvVar(s.Name, "KUBECONFIG")),
+		Path:        s.Path,
+		Windows:     make([]helpers.Window, 0),
 	}
 	for _, window := range s.Windows {
 		session.Windows = append(session.Windows, window.ToHelperStruct())
@@ -129,6 +159,17 @@ func (s *Session) ToHelperStruct() helpers.Session {
 	return session
 }
 
+// Get the forwards declared for the session
+//
+// If they can't be read, none are saved rather than failing the save
+func (s *Session) forwards() []helpers.Forward {
+	forwards, err := portforward.Load(s.Name)
+	if err != nil || len(forwards) == 0 {
+		return nil
+	}
+	return forwards
+}
+
 // Get the session title
 func (s *Session) Title() string {
 	return s.Name
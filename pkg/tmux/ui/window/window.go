@@ -29,8 +29,10 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 	"github.com/mproffitt/bmx/pkg/components/icons"
+	"github.com/mproffitt/bmx/pkg/components/matcher"
 	"github.com/mproffitt/bmx/pkg/helpers"
 	"github.com/mproffitt/bmx/pkg/tmux"
+	"github.com/shirou/gopsutil/v4/process"
 )
 
 type Flag rune
@@ -95,9 +97,8 @@ func new(session, attrStr string) *Window {
 	w := Window{
 		Session: session,
 
-		Active:  attributes[3] != "0",
-		Command: "",
-		Name:    attributes[2],
+		Active: attributes[3] != "0",
+		Name:   attributes[2],
 
 		Flags: map[Flag]bool{
 			Activity:      false,
@@ -122,6 +123,15 @@ func new(session, attrStr string) *Window {
 		log.Debug("error parsing layout ", w.layout)
 		return nil
 	}
+
+	// the command running in the active pane, or its shell
+	// when nothing else is running
+	pid, _ := strconv.ParseInt(attributes[5], 10, 32)
+	if w.Command = w.root.GetCommand(int32(pid)); w.Command == "" {
+		if p, err := process.NewProcess(int32(pid)); err == nil {
+			w.Command, _ = p.Cmdline()
+		}
+	}
 	return &w
 }
 
@@ -200,7 +210,7 @@ func (w *Window) Description() string {
 }
 
 func (w *Window) FilterValue() string {
-	return w.Name
+	return matcher.Join(w.Name, w.Command)
 }
 
 func (w *Window) FindPane(index uint) *Node {
@@ -63,6 +63,30 @@ func CreateWindow(target, name, path, command string, force bool) error {
 	return ExecSilent(args)
 }
 
+// Open a new window in the session and make it the current window
+//
+// Unlike CreateWindow, the window is not created in the background.
+// The ID of the new window is returned
+func NewWindow(session, name, path, command string) (string, error) {
+	args := []string{
+		"new-window", "-P", "-F", "#{window_id}", "-t", session + ":",
+	}
+	if name != "" {
+		args = append(args, "-n", name)
+	}
+	if path != "" {
+		args = append(args, "-c", path)
+	}
+	if command != "" {
+		args = append(args, command)
+	}
+	id, e, err := Exec(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to open window in session %q %q %w", session, e, err)
+	}
+	return id, nil
+}
+
 // Get the layout for a given window
 func GetWindowLayout(target string) (string, error) {
 	layout, _, err := Exec([]string{
@@ -94,7 +118,7 @@ func KillWindow(target string) error {
 func ListWindows(target string) ([]string, error) {
 	args := []string{
 		"list-windows", "-t", target, "-F",
-		"#{window_index},#{window_flags},#{window_name},#{window_active},#{window_panes}",
+		"#{window_index},#{window_flags},#{window_name},#{window_active},#{window_panes},#{pane_pid}",
 	}
 
 	out, _, err := Exec(args)